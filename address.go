@@ -0,0 +1,229 @@
+package sendamatic
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// NormalizeAddress returns a canonical form of an email address for duplicate detection.
+// Display names are removed and the domain is lowercased; the local part is kept as-is
+// because it may be case-sensitive. If stripTag is true, a "+tag" suffix in the local
+// part is removed as well, so that "user+news@example.com" normalizes to
+// "user@example.com". Tag stripping is opt-in since some systems treat tagged
+// addresses as distinct mailboxes.
+// Addresses that cannot be parsed are returned trimmed but otherwise unchanged.
+func NormalizeAddress(email string, stripTag bool) string {
+	email = strings.TrimSpace(email)
+	if addr, err := mail.ParseAddress(email); err == nil {
+		email = addr.Address
+	}
+
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return email
+	}
+	local, domain := email[:at], strings.ToLower(email[at+1:])
+	if stripTag {
+		if plus := strings.Index(local, "+"); plus > 0 {
+			local = local[:plus]
+		}
+	}
+	return local + "@" + domain
+}
+
+// RejectDuplicateRecipients makes Validate fail if the same mailbox appears more than
+// once across To, CC and BCC after normalization with NormalizeAddress.
+// If stripTags is true, "+tag" variants of an address count as duplicates.
+// Returns the message for method chaining.
+func (m *Message) RejectDuplicateRecipients(stripTags bool) *Message {
+	m.rejectDuplicates = true
+	m.stripTags = stripTags
+	return m
+}
+
+// RejectSenderAsRecipient makes Validate fail if the sender's address also appears in
+// To, CC or BCC after normalization with NormalizeAddress, which guards automated flows
+// against accidental self-send loops. It is opt-in because some flows legitimately
+// send a copy to the sender. The error names the field of the collision.
+// Returns the message for method chaining.
+func (m *Message) RejectSenderAsRecipient() *Message {
+	m.rejectSelfSend = true
+	return m
+}
+
+// checkSenderAsRecipient returns an error for every occurrence of the sender in To, CC
+// or BCC.
+func (m *Message) checkSenderAsRecipient() []error {
+	var errs []error
+	sender := NormalizeAddress(m.Sender, false)
+	for _, field := range []struct {
+		name string
+		list []string
+	}{
+		{"to", m.To},
+		{"cc", m.CC},
+		{"bcc", m.BCC},
+	} {
+		for _, email := range field.list {
+			if NormalizeAddress(email, false) == sender {
+				errs = append(errs, invalidf(field.name, "sender %s is also a recipient in %s", sender, field.name))
+			}
+		}
+	}
+	return errs
+}
+
+// checkDuplicateRecipients returns an error for every recipient whose normalized
+// address was already seen in To, CC or BCC.
+func (m *Message) checkDuplicateRecipients() []error {
+	var errs []error
+	seen := make(map[string]bool)
+	for _, field := range []struct {
+		name string
+		list []string
+	}{
+		{"to", m.To},
+		{"cc", m.CC},
+		{"bcc", m.BCC},
+	} {
+		for _, email := range field.list {
+			key := NormalizeAddress(email, m.stripTags)
+			if seen[key] {
+				errs = append(errs, invalidf(field.name, "duplicate recipient: %s", email))
+			}
+			seen[key] = true
+		}
+	}
+	return errs
+}
+
+// checkRecipientVariables returns an error for every recipient variables entry, in
+// sorted order, whose address is not among the normalized To, CC and BCC recipients.
+func (m *Message) checkRecipientVariables() []error {
+	if len(m.RecipientVariables) == 0 {
+		return nil
+	}
+	var errs []error
+	recipients := make(map[string]bool)
+	for _, list := range [][]string{m.To, m.CC, m.BCC} {
+		for _, email := range list {
+			recipients[NormalizeAddress(email, false)] = true
+		}
+	}
+	emails := make([]string, 0, len(m.RecipientVariables))
+	for email := range m.RecipientVariables {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+	for _, email := range emails {
+		if !recipients[NormalizeAddress(email, false)] {
+			errs = append(errs, invalidf("recipient_variables", "recipient variables for %s do not match any recipient", email))
+		}
+	}
+	return errs
+}
+
+// ErrSenderDomainNotAllowed is returned by Send when the sender's domain is not in the
+// allowlist configured with WithAllowedSenderDomains.
+var ErrSenderDomainNotAllowed = errors.New("sendamatic: sender domain not allowed")
+
+// checkSenderDomain returns an error wrapping ErrSenderDomainNotAllowed unless the
+// domain of sender is in the client's allowlist.
+func (c *Client) checkSenderDomain(sender string) error {
+	addr, err := mail.ParseAddress(sender)
+	if err != nil {
+		return fmt.Errorf("%w: cannot parse sender %q: %v", ErrSenderDomainNotAllowed, sender, err)
+	}
+	domain := strings.ToLower(addr.Address[strings.LastIndex(addr.Address, "@")+1:])
+	if !c.senderDomains[domain] {
+		return fmt.Errorf("%w: %s", ErrSenderDomainNotAllowed, domain)
+	}
+	return nil
+}
+
+// ErrAllRecipientsSuppressed is returned by Send without contacting the API when every
+// recipient of a message is on the suppression list configured with
+// WithSuppressionList.
+var ErrAllRecipientsSuppressed = errors.New("sendamatic: all recipients are suppressed")
+
+// withoutSuppressed returns a copy of msg without the recipients reported by the
+// client's suppression list, together with the removed entries in the order they
+// appeared in To, CC and BCC. Recipient variables of removed recipients are dropped.
+// If nothing is suppressed, msg itself is returned.
+func (c *Client) withoutSuppressed(msg *Message) (*Message, []string) {
+	var suppressed []string
+	keep := func(list []string) []string {
+		kept := make([]string, 0, len(list))
+		for _, email := range list {
+			if c.suppressed(NormalizeAddress(email, false)) {
+				suppressed = append(suppressed, email)
+			} else {
+				kept = append(kept, email)
+			}
+		}
+		return kept
+	}
+
+	to, cc, bcc := keep(msg.To), keep(msg.CC), keep(msg.BCC)
+	if len(suppressed) == 0 {
+		return msg, nil
+	}
+
+	filtered := msg.Clone()
+	filtered.To, filtered.CC, filtered.BCC = to, cc, bcc
+	if msg.RecipientVariables != nil {
+		filtered.RecipientVariables = chunkVariables(filtered, msg.RecipientVariables)
+	}
+	return filtered, suppressed
+}
+
+// EmailAddress is an email address with an optional display name. It lets
+// applications pass validated addresses around instead of bare strings.
+type EmailAddress struct {
+	Name    string
+	Address string
+}
+
+// ParseEmailAddress parses a single RFC 5322 address such as "jane@example.com" or
+// "Jane Doe <jane@example.com>". RFC 2047 encoded display names are decoded.
+func ParseEmailAddress(s string) (EmailAddress, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return EmailAddress{}, fmt.Errorf("invalid email address %q: %w", s, err)
+	}
+	return EmailAddress{Name: addr.Name, Address: addr.Address}, nil
+}
+
+// String formats the address in RFC 5322 form, quoting and encoding the display name
+// as needed, e.g. `"Doe, Jane" <jane@example.com>`. Without a name, the bare address
+// is returned.
+func (a EmailAddress) String() string {
+	return formatAddress(a.Name, a.Address)
+}
+
+// AddToAddr adds a To recipient from an EmailAddress.
+// Returns the message for method chaining.
+func (m *Message) AddToAddr(addr EmailAddress) *Message {
+	return m.AddTo(addr.String())
+}
+
+// AddCCAddr adds a CC recipient from an EmailAddress.
+// Returns the message for method chaining.
+func (m *Message) AddCCAddr(addr EmailAddress) *Message {
+	return m.AddCC(addr.String())
+}
+
+// AddBCCAddr adds a BCC recipient from an EmailAddress.
+// Returns the message for method chaining.
+func (m *Message) AddBCCAddr(addr EmailAddress) *Message {
+	return m.AddBCC(addr.String())
+}
+
+// SetSenderAddr sets the sender from an EmailAddress.
+// Returns the message for method chaining.
+func (m *Message) SetSenderAddr(addr EmailAddress) *Message {
+	return m.SetSender(addr.String())
+}
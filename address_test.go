@@ -0,0 +1,277 @@
+package sendamatic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		stripTag bool
+		want     string
+	}{
+		{"lowercases domain", "User@Example.COM", false, "User@example.com"},
+		{"keeps tag by default", "user+news@example.com", false, "user+news@example.com"},
+		{"strips tag", "user+news@Example.com", true, "user@example.com"},
+		{"removes display name", "Jane Doe <jane@Example.com>", false, "jane@example.com"},
+		{"trims whitespace", "  user@example.com ", false, "user@example.com"},
+		{"leading plus is kept", "+tag@example.com", true, "+tag@example.com"},
+		{"invalid address", "not-an-address", true, "not-an-address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeAddress(tt.email, tt.stripTag); got != tt.want {
+				t.Errorf("NormalizeAddress(%q, %v) = %q, want %q", tt.email, tt.stripTag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectDuplicateRecipients(t *testing.T) {
+	base := func() *Message {
+		return NewMessage().
+			SetSender("sender@example.com").
+			SetSubject("Subject").
+			SetTextBody("Body").
+			AddTo("user@example.com").
+			AddBCC("user+tag@EXAMPLE.com")
+	}
+
+	if err := base().Validate(); err != nil {
+		t.Errorf("Validate() without duplicate check error = %v, want nil", err)
+	}
+
+	if err := base().RejectDuplicateRecipients(false).Validate(); err != nil {
+		t.Errorf("Validate() with tags kept error = %v, want nil", err)
+	}
+
+	err := base().RejectDuplicateRecipients(true).Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want duplicate recipient error")
+	}
+	if err.Error() != "duplicate recipient: user+tag@EXAMPLE.com" {
+		t.Errorf("Validate() error = %q", err.Error())
+	}
+
+	err = base().AddCC("User@Example.com").RejectDuplicateRecipients(false).Validate()
+	if err != nil {
+		t.Errorf("Validate() error = %v, want nil for differing local part case", err)
+	}
+
+	err = base().AddCC("user@Example.com").RejectDuplicateRecipients(false).Validate()
+	if err == nil {
+		t.Error("Validate() error = nil, want duplicate recipient error")
+	}
+}
+
+func TestWithAllowedSenderDomains(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithAllowedSenderDomains([]string{"Example.com", "tenant.org"}))
+
+	tests := []struct {
+		sender  string
+		wantErr string
+	}{
+		{"sender@example.com", ""},
+		{"Sender <noreply@EXAMPLE.COM>", ""},
+		{"info@tenant.org", ""},
+		{"sender@mail.example.com", "sendamatic: sender domain not allowed: mail.example.com"},
+		{"sender@other.net", "sendamatic: sender domain not allowed: other.net"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sender, func(t *testing.T) {
+			_, err := client.Send(context.Background(), validMessage().SetSender(tt.sender))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Send() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrSenderDomainNotAllowed) || err.Error() != tt.wantErr {
+				t.Errorf("Send() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+
+	for _, domains := range [][]string{nil, {}, {" "}} {
+		client := NewClient("user", "pass", WithBaseURL(server.URL), WithAllowedSenderDomains(domains))
+		if _, err := client.Send(context.Background(), validMessage()); err != nil {
+			t.Errorf("Send() with domains %q error = %v, want nil", domains, err)
+		}
+	}
+}
+
+func TestWithSuppressionList(t *testing.T) {
+	var requests atomic.Int32
+	var sent Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		json.NewDecoder(r.Body).Decode(&sent)
+		w.Write([]byte(`{"keep@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	suppressed := map[string]bool{"gone@example.com": true, "bounced@example.com": true}
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithSuppressionList(func(email string) bool { return suppressed[email] }))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("keep@example.com").
+		AddTo("Gone <gone@EXAMPLE.com>").
+		AddBCC("bounced@example.com").
+		SetSubject("Test").
+		SetTextBody("Body").
+		AddToWithVars("gone@example.com", map[string]interface{}{"name": "Gone"})
+
+	resp, err := client.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !reflect.DeepEqual(sent.To, []string{"keep@example.com"}) || len(sent.BCC) != 0 {
+		t.Errorf("sent To = %v, BCC = %v, want only keep@example.com", sent.To, sent.BCC)
+	}
+	if sent.RecipientVariables != nil {
+		t.Errorf("sent RecipientVariables = %v, want nil", sent.RecipientVariables)
+	}
+	want := []string{"Gone <gone@EXAMPLE.com>", "gone@example.com", "bounced@example.com"}
+	if !reflect.DeepEqual(resp.Suppressed, want) {
+		t.Errorf("Suppressed = %v, want %v", resp.Suppressed, want)
+	}
+	if len(msg.To) != 3 || len(msg.BCC) != 1 {
+		t.Error("caller's message was modified")
+	}
+
+	_, err = client.Send(context.Background(), validMessage().SetSender("sender@example.com").AddTo("gone@example.com"))
+	if err != nil {
+		t.Fatalf("Send() error = %v, want nil with one recipient left", err)
+	}
+
+	_, err = client.Send(context.Background(), NewSimpleMessage("sender@example.com", "gone@example.com", "Test", "Body"))
+	if !errors.Is(err, ErrAllRecipientsSuppressed) {
+		t.Errorf("Send() error = %v, want ErrAllRecipientsSuppressed", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestWithoutSuppressed_NothingSuppressed(t *testing.T) {
+	client := NewClient("user", "pass",
+		WithSuppressionList(func(email string) bool { return email == "gone@example.com" }))
+
+	msg := validMessage()
+	got, suppressed := client.withoutSuppressed(msg)
+	if got != msg {
+		t.Error("withoutSuppressed() returned a copy, want the original message")
+	}
+	if suppressed != nil {
+		t.Errorf("suppressed = %v, want nil", suppressed)
+	}
+}
+
+func TestRejectSenderAsRecipient(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     *Message
+		wantErr string
+	}{
+		{
+			name: "no collision",
+			msg:  validMessage(),
+		},
+		{
+			name:    "sender in to",
+			msg:     validMessage().SetSender("Bot <bot@Example.com>").AddTo("bot@example.com"),
+			wantErr: "sender bot@example.com is also a recipient in to",
+		},
+		{
+			name:    "sender in bcc",
+			msg:     validMessage().SetSender("bot@example.com").AddBCC("Archive <bot@EXAMPLE.com>"),
+			wantErr: "sender bot@example.com is also a recipient in bcc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.msg.Validate(); err != nil {
+				t.Fatalf("Validate() without check error = %v, want nil", err)
+			}
+			err := tt.msg.RejectSenderAsRecipient().ValidateStrict()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateStrict() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ValidateStrict() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseEmailAddress(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    EmailAddress
+		wantStr string
+		wantErr bool
+	}{
+		{"jane@example.com", EmailAddress{Address: "jane@example.com"}, "jane@example.com", false},
+		{"Jane Doe <jane@example.com>", EmailAddress{Name: "Jane Doe", Address: "jane@example.com"}, `"Jane Doe" <jane@example.com>`, false},
+		{`"Doe, Jane" <jane@example.com>`, EmailAddress{Name: "Doe, Jane", Address: "jane@example.com"}, `"Doe, Jane" <jane@example.com>`, false},
+		{"=?utf-8?q?J=C3=BCrgen?= <j@example.com>", EmailAddress{Name: "Jürgen", Address: "j@example.com"}, "=?utf-8?q?J=C3=BCrgen?= <j@example.com>", false},
+		{"not an address", EmailAddress{}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseEmailAddress(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEmailAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseEmailAddress() = %+v, want %+v", got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.wantStr {
+				t.Errorf("String() = %q, want %q", got.String(), tt.wantStr)
+			}
+		})
+	}
+}
+
+func TestEmailAddressBuilders(t *testing.T) {
+	addr := EmailAddress{Name: "Doe, Jane", Address: "jane@example.com"}
+	msg := NewMessage().SetSenderAddr(addr).AddToAddr(addr).AddCCAddr(addr).AddBCCAddr(addr)
+
+	want := `"Doe, Jane" <jane@example.com>`
+	for field, got := range map[string]string{"Sender": msg.Sender, "To": msg.To[0], "CC": msg.CC[0], "BCC": msg.BCC[0]} {
+		if got != want {
+			t.Errorf("%s = %q, want %q", field, got, want)
+		}
+	}
+}
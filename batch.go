@@ -0,0 +1,152 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// BatchStatus describes the outcome of a single message in a batch sent with SendBatch.
+type BatchStatus int
+
+const (
+	// BatchNotAttempted indicates that the message was not sent because the batch
+	// was interrupted before reaching it.
+	BatchNotAttempted BatchStatus = iota
+	// BatchSent indicates that the message was accepted by the API.
+	BatchSent
+	// BatchFailed indicates that sending the message returned an error.
+	BatchFailed
+)
+
+// String returns a human-readable name for the batch status.
+func (s BatchStatus) String() string {
+	switch s {
+	case BatchSent:
+		return "sent"
+	case BatchFailed:
+		return "failed"
+	default:
+		return "not attempted"
+	}
+}
+
+// BatchResult holds the outcome of sending one message of a batch.
+// Response is set for sent messages and Err for failed ones. A failed message may also
+// carry a Response, e.g. when WithFailOnPartialFailure reports rejected recipients.
+type BatchResult struct {
+	Status   BatchStatus
+	Response *SendResponse
+	Err      error
+}
+
+// SendBatch sends the given messages one after another and returns one BatchResult
+// per message, in the same order as msgs. Failures of individual messages are
+// recorded in their results and do not stop the batch.
+//
+// If the context is canceled or its deadline is exceeded, SendBatch stops and returns
+// the results accumulated so far together with the context error. Messages that were
+// not reached keep the status BatchNotAttempted, so callers can resume the batch or
+// report accurately. If the batch runs to completion, the returned error is nil.
+func (c *Client) SendBatch(ctx context.Context, msgs []*Message) ([]BatchResult, error) {
+	results := make([]BatchResult, len(msgs))
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		resp, err := c.Send(ctx, msg)
+		if err != nil {
+			results[i] = BatchResult{Status: BatchFailed, Response: resp, Err: err}
+			continue
+		}
+		results[i] = BatchResult{Status: BatchSent, Response: resp}
+	}
+
+	return results, ctx.Err()
+}
+
+// SendChunked sends a message whose To list may exceed the API's limit of 255
+// recipients by splitting it into several sends of at most chunkSize To recipients
+// each. A chunkSize of 0 or more than the client's limit, 255 unless changed with
+// WithMaxRecipients, uses that limit. Each chunk is sent
+// as a clone of msg; CC and BCC recipients are included in the first chunk only so
+// they receive the message once, and each chunk carries only the recipient variables
+// of its own recipients.
+//
+// The Recipients of all successful chunks are merged into a single SendResponse,
+// whose StatusCode and Header are taken from the first successful chunk.
+// A failing chunk does not prevent the remaining chunks from being sent; the errors
+// of all failed chunks are joined and returned together with the merged response.
+// If no chunk succeeds, the response is nil.
+func (c *Client) SendChunked(ctx context.Context, msg *Message, chunkSize int) (*SendResponse, error) {
+	if msg == nil {
+		return nil, ErrNilMessage
+	}
+	if chunkSize <= 0 || chunkSize > c.maxRecipients {
+		chunkSize = c.maxRecipients
+	}
+
+	var chunks [][]string
+	for start := 0; start < len(msg.To); start += chunkSize {
+		chunks = append(chunks, msg.To[start:min(start+chunkSize, len(msg.To))])
+	}
+	if len(chunks) == 0 {
+		// Messages with only CC or BCC recipients are sent as a single chunk
+		chunks = [][]string{{}}
+	}
+
+	var merged *SendResponse
+	var errs []error
+	for i, to := range chunks {
+		chunk := msg.Clone()
+		chunk.To = append([]string{}, to...)
+		if i > 0 {
+			chunk.CC = []string{}
+			chunk.BCC = []string{}
+		}
+		if msg.RecipientVariables != nil {
+			chunk.RecipientVariables = chunkVariables(chunk, msg.RecipientVariables)
+		}
+
+		resp, err := c.Send(ctx, chunk)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("chunk %d: %w", i, err))
+		}
+		if resp == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = &SendResponse{
+				StatusCode: resp.StatusCode,
+				Recipients: make(map[string][]interface{}),
+				Header:     resp.Header,
+			}
+		}
+		for email, info := range resp.Recipients {
+			merged.Recipients[email] = info
+		}
+		merged.Suppressed = append(merged.Suppressed, resp.Suppressed...)
+	}
+
+	return merged, errors.Join(errs...)
+}
+
+// chunkVariables returns the entries of vars that belong to the recipients of chunk,
+// or nil if there are none.
+func chunkVariables(chunk *Message, vars map[string]map[string]interface{}) map[string]map[string]interface{} {
+	var filtered map[string]map[string]interface{}
+	for _, list := range [][]string{chunk.To, chunk.CC, chunk.BCC} {
+		for _, email := range list {
+			key := NormalizeAddress(email, false)
+			if v, ok := vars[key]; ok {
+				if filtered == nil {
+					filtered = make(map[string]map[string]interface{})
+				}
+				filtered[key] = v
+			}
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,204 @@
+package sendamatic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBatchStatus_String(t *testing.T) {
+	tests := []struct {
+		status BatchStatus
+		want   string
+	}{
+		{BatchNotAttempted, "not attempted"},
+		{BatchSent, "sent"},
+		{BatchFailed, "failed"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestClient_SendBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"to@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	msgs := []*Message{validMessage(), NewMessage(), validMessage()}
+
+	results, err := client.SendBatch(context.Background(), msgs)
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v, want nil", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Results length = %d, want 3", len(results))
+	}
+
+	want := []BatchStatus{BatchSent, BatchFailed, BatchSent}
+	for i, res := range results {
+		if res.Status != want[i] {
+			t.Errorf("Results[%d].Status = %v, want %v", i, res.Status, want[i])
+		}
+	}
+	if results[0].Response == nil || !results[0].Response.IsSuccess() {
+		t.Error("Expected successful response for first message")
+	}
+	if results[1].Err == nil {
+		t.Error("Expected error for invalid message")
+	}
+}
+
+func TestClient_SendBatch_CanceledMidBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"to@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	msgs := []*Message{validMessage(), validMessage(), validMessage(), validMessage()}
+
+	results, err := client.SendBatch(ctx, msgs)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SendBatch() error = %v, want context.Canceled", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("Results length = %d, want 4", len(results))
+	}
+	if results[0].Status != BatchSent {
+		t.Errorf("Results[0].Status = %v, want sent", results[0].Status)
+	}
+	if results[1].Status == BatchNotAttempted {
+		t.Error("Results[1].Status = not attempted, want sent or failed")
+	}
+	for i := 2; i < 4; i++ {
+		if results[i].Status != BatchNotAttempted {
+			t.Errorf("Results[%d].Status = %v, want not attempted", i, results[i].Status)
+		}
+	}
+}
+
+func TestClient_SendChunked(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+
+		if n == 1 && (len(msg.CC) != 1 || len(msg.BCC) != 1) {
+			t.Errorf("First chunk CC/BCC = %v/%v, want one each", msg.CC, msg.BCC)
+		}
+		if n > 1 && (len(msg.CC) != 0 || len(msg.BCC) != 0) {
+			t.Errorf("Chunk %d CC/BCC = %v/%v, want none", n, msg.CC, msg.BCC)
+		}
+		if len(msg.To) > 2 {
+			t.Errorf("Chunk %d has %d To recipients, want at most 2", n, len(msg.To))
+		}
+
+		// Fail the second chunk
+		if n == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": "Internal server error"}`))
+			return
+		}
+
+		response := map[string][]interface{}{}
+		for _, email := range msg.To {
+			response[email] = []interface{}{float64(200), "msg-" + email}
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	msg := validMessage().AddCC("cc@example.com").AddBCC("bcc@example.com")
+	msg.To = []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"}
+
+	resp, err := client.SendChunked(context.Background(), msg, 2)
+	if calls != 3 {
+		t.Errorf("Server calls = %d, want 3", calls)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Error type = %T, want to wrap *APIError", err)
+	}
+	if !strings.Contains(err.Error(), "chunk 1") {
+		t.Errorf("Error = %q, want to identify chunk 1", err.Error())
+	}
+
+	if resp == nil {
+		t.Fatal("Expected merged response for successful chunks")
+	}
+	for _, email := range []string{"a@example.com", "b@example.com", "e@example.com"} {
+		if _, ok := resp.GetStatus(email); !ok {
+			t.Errorf("Merged response missing %s", email)
+		}
+	}
+	if len(resp.Recipients) != 3 {
+		t.Errorf("Merged recipients = %d, want 3", len(resp.Recipients))
+	}
+
+	// The original message must remain untouched
+	if len(msg.To) != 5 || len(msg.CC) != 1 {
+		t.Error("SendChunked modified the original message")
+	}
+}
+
+func TestClient_SendChunked_RecipientVariables(t *testing.T) {
+	var chunks []map[string]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		json.NewDecoder(r.Body).Decode(&msg)
+		chunks = append(chunks, msg.RecipientVariables)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		SetSubject("Test").
+		SetTextBody("Hello").
+		AddToWithVars("a@example.com", map[string]interface{}{"name": "A"}).
+		AddTo("b@example.com").
+		AddToWithVars("c@example.com", map[string]interface{}{"name": "C"})
+
+	if _, err := client.SendChunked(context.Background(), msg, 2); err != nil {
+		t.Fatalf("SendChunked() error = %v", err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("chunks = %d, want 2", len(chunks))
+	}
+	if _, ok := chunks[0]["a@example.com"]; !ok || len(chunks[0]) != 1 {
+		t.Errorf("chunk 0 variables = %v, want only a@example.com", chunks[0])
+	}
+	if _, ok := chunks[1]["c@example.com"]; !ok || len(chunks[1]) != 1 {
+		t.Errorf("chunk 1 variables = %v, want only c@example.com", chunks[1])
+	}
+}
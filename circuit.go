@@ -0,0 +1,130 @@
+package sendamatic
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Send when the circuit breaker configured with
+// WithCircuitBreaker is open and the request was rejected without contacting the API.
+var ErrCircuitOpen = errors.New("sendamatic: circuit breaker is open")
+
+// CircuitState describes the state of the client's circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through. This is the normal state.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests with ErrCircuitOpen until the cooldown elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through after the cooldown. Its
+	// success closes the circuit, its failure opens it again.
+	CircuitHalfOpen
+)
+
+// String returns a lowercase name for the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker tracks consecutive failed sends within a window and decides whether
+// new requests may be made. The current time is passed in by the caller so that the
+// breaker follows the client's clock.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration // zero counts consecutive failures regardless of age
+	cooldown  time.Duration
+
+	mu           sync.Mutex
+	state        CircuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+// allow reports whether a request may be made at the given time. In the half-open
+// state only one trial request is allowed at a time.
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.cooldown {
+		b.state = CircuitHalfOpen
+	}
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return false
+	}
+}
+
+// record updates the breaker with the outcome of a request permitted by allow.
+func (b *circuitBreaker) record(now time.Time, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !failed {
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+	if b.failures == 0 || (b.window > 0 && now.Sub(b.firstFailure) > b.window) {
+		// Failures older than the window no longer count towards the threshold
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = now
+	}
+}
+
+// release returns a request permitted by allow without counting its outcome, so
+// that a canceled trial request does not block the half-open state.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// currentState returns the state at the given time, reporting an open circuit whose
+// cooldown has elapsed as half-open.
+func (b *circuitBreaker) currentState(now time.Time) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// CircuitState returns the current state of the client's circuit breaker. It returns
+// CircuitClosed if no circuit breaker was configured with WithCircuitBreaker.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.currentState(c.now())
+}
@@ -0,0 +1,174 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitState_String(t *testing.T) {
+	tests := []struct {
+		state CircuitState
+		want  string
+	}{
+		{CircuitClosed, "closed"},
+		{CircuitOpen, "open"},
+		{CircuitHalfOpen, "half-open"},
+		{CircuitState(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.String(); got != tt.want {
+			t.Errorf("CircuitState(%d).String() = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	var calls, status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(int(status.Load()))
+		w.Write([]byte(`{"recipient@example.com": [250, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithCircuitBreaker(2, 0, time.Minute),
+		WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Send(ctx, validMessage()); errors.Is(err, ErrCircuitOpen) || err == nil {
+			t.Fatalf("Send() #%d error = %v, want API error", i+1, err)
+		}
+	}
+	if got := client.CircuitState(); got != CircuitOpen {
+		t.Fatalf("CircuitState() = %v, want %v", got, CircuitOpen)
+	}
+
+	if _, err := client.Send(ctx, validMessage()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Send() while open error = %v, want ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	// A failed trial after the cooldown opens the circuit again
+	now = now.Add(time.Minute)
+	if got := client.CircuitState(); got != CircuitHalfOpen {
+		t.Errorf("CircuitState() after cooldown = %v, want %v", got, CircuitHalfOpen)
+	}
+	if _, err := client.Send(ctx, validMessage()); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Send() trial error = %v, want API error", err)
+	}
+	if got := client.CircuitState(); got != CircuitOpen {
+		t.Errorf("CircuitState() after failed trial = %v, want %v", got, CircuitOpen)
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	status.Store(http.StatusOK)
+	if _, err := client.Send(ctx, validMessage()); err != nil {
+		t.Errorf("Send() trial error = %v, want nil", err)
+	}
+	if got := client.CircuitState(); got != CircuitClosed {
+		t.Errorf("CircuitState() after successful trial = %v, want %v", got, CircuitClosed)
+	}
+}
+
+func TestWithCircuitBreaker_IgnoresClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "bad request"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithCircuitBreaker(1, 0, time.Minute))
+
+	for i := 0; i < 3; i++ {
+		var apiErr *APIError
+		if _, err := client.Send(context.Background(), validMessage()); !errors.As(err, &apiErr) {
+			t.Errorf("Send() #%d error = %v, want *APIError", i+1, err)
+		}
+	}
+	if _, err := client.Send(context.Background(), NewMessage()); errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Send() invalid message error = %v, want validation error", err)
+	}
+	if got := client.CircuitState(); got != CircuitClosed {
+		t.Errorf("CircuitState() = %v, want %v", got, CircuitClosed)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleTrial(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{threshold: 1, cooldown: time.Second}
+
+	b.record(now, true)
+	if b.allow(now) {
+		t.Error("allow() while open = true, want false")
+	}
+
+	now = now.Add(time.Second)
+	if !b.allow(now) {
+		t.Fatal("allow() first trial = false, want true")
+	}
+	if b.allow(now) {
+		t.Error("allow() second trial = true, want false")
+	}
+
+	b.release()
+	if !b.allow(now) {
+		t.Error("allow() after release = false, want true")
+	}
+}
+
+func TestCircuitBreaker_Window(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := &circuitBreaker{threshold: 3, window: time.Minute, cooldown: time.Hour}
+
+	// Failures spread over more than the window do not open the circuit
+	for i := 0; i < 5; i++ {
+		b.record(now, true)
+		now = now.Add(40 * time.Second)
+	}
+	if got := b.currentState(now); got != CircuitClosed {
+		t.Fatalf("currentState() after sparse failures = %v, want %v", got, CircuitClosed)
+	}
+
+	// Failures within the window do
+	for i := 0; i < 3; i++ {
+		b.record(now, true)
+		now = now.Add(10 * time.Second)
+	}
+	if got := b.currentState(now); got != CircuitOpen {
+		t.Errorf("currentState() after failures within the window = %v, want %v", got, CircuitOpen)
+	}
+
+	// Without a window, sparse failures count as well
+	b = &circuitBreaker{threshold: 3, cooldown: time.Second}
+	for i := 0; i < 3; i++ {
+		b.record(now, true)
+		now = now.Add(time.Hour)
+	}
+	if got := b.currentState(now.Add(-time.Hour)); got != CircuitOpen {
+		t.Errorf("currentState() without window = %v, want %v", got, CircuitOpen)
+	}
+}
+
+func TestClient_CircuitState_Disabled(t *testing.T) {
+	client := NewClient("user", "pass", WithCircuitBreaker(0, 0, time.Minute))
+	if client.breaker != nil {
+		t.Error("breaker configured with threshold 0, want nil")
+	}
+	if got := client.CircuitState(); got != CircuitClosed {
+		t.Errorf("CircuitState() = %v, want %v", got, CircuitClosed)
+	}
+}
@@ -16,11 +16,19 @@ package sendamatic
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -29,14 +37,67 @@ const (
 	defaultBaseURL = "https://send.api.sendamatic.net"
 	// defaultTimeout is the default HTTP client timeout for API requests.
 	defaultTimeout = 30 * time.Second
+	// compressionThreshold is the minimum payload size in bytes before gzip
+	// compression is applied when enabled via WithCompression.
+	compressionThreshold = 1024
+	// defaultMaxResponseBytes is the default maximum size of a response body.
+	defaultMaxResponseBytes = 10 << 20
+	// defaultAuthHeader is the request header carrying the API key.
+	defaultAuthHeader = "x-api-key"
 )
 
 // Client represents a Sendamatic API client that handles authentication and HTTP communication
 // with the Sendamatic email delivery service.
+//
+// A Client is safe for concurrent use by multiple goroutines once it has been created,
+// and should be reused rather than created per request. Send never modifies the Message
+// passed to it, so the same Message may be sent concurrently as long as no goroutine
+// modifies it at the same time. Hooks such as Metrics must be safe for concurrent use.
 type Client struct {
 	apiKey     string
+	authHeader string
+	authFormat string
 	baseURL    string
+	baseURLErr error
+	proxyURL   *url.URL
+	proxyErr   error
 	httpClient *http.Client
+	compress   bool
+
+	// ownTransport is the transport created by tunableTransport, if any, and
+	// callerTransport reports whether it was cloned from a caller's transport.
+	// ownClient reports whether httpClient was created by the client rather than
+	// passed to WithHTTPClient.
+	ownTransport    *http.Transport
+	callerTransport bool
+	ownClient       bool
+
+	maxResponseBytes     int64
+	maxRecipients        int
+	maxAttachmentSize    int64
+	requestTimeout       time.Duration
+	insecureSkipVerify   bool
+	tlsConfig            *tls.Config
+	skipValidation       bool
+	strictValidation     bool
+	htmlValidation       bool
+	retryPolicy          RetryPolicy
+	jitter               JitterMode
+	metrics              Metrics
+	logger               *slog.Logger
+	logContextKeys       []logContextKey
+	breaker              *circuitBreaker
+	middleware           []Middleware
+	senderDomains        map[string]bool
+	suppressed           func(email string) bool
+	noHTMLEscape         bool
+	failOnPartialFailure bool
+	testMode             bool
+	now                  func() time.Time
+	defaultHeaders       []Header
+	contextMetadata      func(ctx context.Context) map[string]string
+
+	responseInterceptor func(statusCode int, body []byte)
 }
 
 // NewClient creates and returns a new Client configured with the provided Sendamatic credentials.
@@ -48,12 +109,19 @@ type Client struct {
 //	client := sendamatic.NewClient("user-id", "password",
 //		sendamatic.WithTimeout(60*time.Second))
 func NewClient(userID, password string, opts ...Option) *Client {
+	defaultHTTPClient := &http.Client{
+		Timeout: defaultTimeout,
+	}
 	c := &Client{
-		apiKey:  fmt.Sprintf("%s-%s", userID, password),
-		baseURL: defaultBaseURL,
-		httpClient: &http.Client{
-			Timeout: defaultTimeout,
-		},
+		apiKey:           fmt.Sprintf("%s-%s", userID, password),
+		authHeader:       defaultAuthHeader,
+		authFormat:       "%s",
+		baseURL:          defaultBaseURL,
+		httpClient:       defaultHTTPClient,
+		ownClient:        true,
+		maxResponseBytes: defaultMaxResponseBytes,
+		maxRecipients:    maxRecipients,
+		now:              time.Now,
 	}
 
 	// Apply configuration options
@@ -61,42 +129,282 @@ func NewClient(userID, password string, opts ...Option) *Client {
 		opt(c)
 	}
 
+	if c.proxyURL != nil && c.proxyErr == nil {
+		if t := c.tunableTransport(); t != nil {
+			t.Proxy = http.ProxyURL(c.proxyURL)
+		} else {
+			c.proxyErr = fmt.Errorf("cannot use proxy %s: transport %T is not an *http.Transport",
+				c.proxyURL.Redacted(), c.httpClient.Transport)
+		}
+	}
+
+	// Only change TLS settings on a client and transport we created ourselves
+	if (c.tlsConfig != nil || c.insecureSkipVerify) && c.httpClient == defaultHTTPClient && !c.callerTransport {
+		if rt := c.httpClient.Transport; rt == nil || rt == http.RoundTripper(c.ownTransport) {
+			cfg := &tls.Config{}
+			if c.tlsConfig != nil {
+				cfg = c.tlsConfig.Clone()
+			}
+			if c.insecureSkipVerify {
+				cfg.InsecureSkipVerify = true
+			}
+			c.tunableTransport().TLSClientConfig = cfg
+		}
+	}
+
 	return c
 }
 
+// Environment variables read by NewClientFromEnv.
+const (
+	// EnvUser is the environment variable holding the Mail Credential User ID.
+	EnvUser = "SENDAMATIC_USER"
+	// EnvPassword is the environment variable holding the Mail Credential Password.
+	EnvPassword = "SENDAMATIC_PASSWORD"
+	// EnvBaseURL is the optional environment variable overriding the API base URL.
+	EnvBaseURL = "SENDAMATIC_BASE_URL"
+)
+
+// NewClientFromEnv creates a Client using credentials from the environment variables
+// SENDAMATIC_USER and SENDAMATIC_PASSWORD, which are required. If SENDAMATIC_BASE_URL
+// is set, it is used as the API base URL. Additional options are applied afterwards
+// and therefore take precedence over the environment.
+//
+// Example:
+//
+//	client, err := sendamatic.NewClientFromEnv(
+//		sendamatic.WithTimeout(60*time.Second))
+func NewClientFromEnv(opts ...Option) (*Client, error) {
+	userID := os.Getenv(EnvUser)
+	password := os.Getenv(EnvPassword)
+
+	var missing []string
+	if userID == "" {
+		missing = append(missing, EnvUser)
+	}
+	if password == "" {
+		missing = append(missing, EnvPassword)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	if baseURL := os.Getenv(EnvBaseURL); baseURL != "" {
+		opts = append([]Option{WithBaseURL(baseURL)}, opts...)
+	}
+
+	return NewClient(userID, password, opts...), nil
+}
+
+// tunableTransport returns the client's own *http.Transport so that options can adjust
+// it. Transports and HTTP clients supplied by the caller are never modified, and
+// neither is http.DefaultTransport: on first use, the current transport, or
+// http.DefaultTransport if none is set, is cloned and installed on the client's HTTP
+// client, which is copied first if it was passed to WithHTTPClient. It returns nil if
+// the client uses a RoundTripper of another type.
+func (c *Client) tunableTransport() *http.Transport {
+	rt := c.httpClient.Transport
+	if c.ownTransport != nil && rt == http.RoundTripper(c.ownTransport) {
+		return c.ownTransport
+	}
+
+	var base *http.Transport
+	switch t := rt.(type) {
+	case nil:
+		base, _ = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		base = t
+	}
+	if base == nil {
+		return nil
+	}
+
+	c.ownTransport = base.Clone()
+	c.callerTransport = rt != nil
+	c.ownHTTPClient().Transport = c.ownTransport
+	return c.ownTransport
+}
+
+// ownHTTPClient returns the client's HTTP client for modification, replacing an
+// http.Client passed to WithHTTPClient with a copy first so that the caller's value
+// is left unchanged.
+func (c *Client) ownHTTPClient() *http.Client {
+	if !c.ownClient {
+		hc := *c.httpClient
+		c.httpClient = &hc
+		c.ownClient = true
+	}
+	return c.httpClient
+}
+
+// MaskedAPIKey returns the client's API key with all but the last 4 characters replaced
+// by asterisks. It is intended for troubleshooting authentication problems in logs
+// without exposing the secret. Keys of 4 characters or fewer are masked entirely.
+func (c *Client) MaskedAPIKey() string {
+	if len(c.apiKey) <= 4 {
+		return strings.Repeat("*", len(c.apiKey))
+	}
+	return strings.Repeat("*", len(c.apiKey)-4) + c.apiKey[len(c.apiKey)-4:]
+}
+
+// Close releases idle pooled connections of the client's HTTP transport, for example
+// during a graceful shutdown of a service that has stopped sending. It calls
+// CloseIdleConnections on the HTTP client, which only has an effect if the transport
+// supports it, as http.Transport does. Connections in use are not interrupted.
+//
+// Calling Close is optional, and the client remains fully usable afterwards: new
+// connections are opened as needed. Close always returns nil; the error result lets
+// the Client satisfy io.Closer.
+func (c *Client) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
+
 // Send sends an email message through the Sendamatic API using the provided context.
 // The message is validated before sending. If validation fails or the API request fails,
 // an error is returned. On success, a SendResponse containing per-recipient delivery
 // information is returned.
 //
 // The context can be used to set deadlines, timeouts, or cancel the request.
+// Passing a nil message returns ErrNilMessage.
 func (c *Client) Send(ctx context.Context, msg *Message) (*SendResponse, error) {
-	if err := msg.Validate(); err != nil {
-		return nil, fmt.Errorf("message validation failed: %w", err)
+	return c.instrumentedSend(ctx, msg, nil)
+}
+
+// SendWithIdempotencyKey works like Send but attaches the given key as an
+// "Idempotency-Key" request header, allowing the API to deduplicate repeated
+// submissions of the same logical message. The same key is reused for every
+// attempt when retries are enabled.
+//
+// The key should be stable per logical message, e.g. a UUID generated once when the
+// email is enqueued and stored alongside it, so that a Send retried after a timeout
+// or restart carries the same key.
+func (c *Client) SendWithIdempotencyKey(ctx context.Context, msg *Message, key string) (*SendResponse, error) {
+	header := http.Header{}
+	header.Set("Idempotency-Key", key)
+	return c.instrumentedSend(ctx, msg, header)
+}
+
+// instrumentedSend runs send through the middleware chain and reports the outcome to
+// the configured metrics and logger.
+func (c *Client) instrumentedSend(ctx context.Context, msg *Message, header http.Header) (*SendResponse, error) {
+	send := c.sendChain(header)
+	if c.metrics == nil && c.logger == nil {
+		return send(ctx, msg)
+	}
+
+	start := c.now()
+	resp, err := send(ctx, msg)
+	duration, statusCode := c.now().Sub(start), observedStatusCode(resp, err)
+	if c.metrics != nil {
+		c.metrics.ObserveSend(duration, statusCode, err)
+	}
+	if c.logger != nil {
+		c.logSend(ctx, msg, duration, statusCode, err)
+	}
+	return resp, err
+}
+
+// send validates, encodes and submits msg, adding the given extra request headers.
+func (c *Client) send(ctx context.Context, msg *Message, header http.Header) (*SendResponse, error) {
+	if msg == nil {
+		return nil, ErrNilMessage
+	}
+
+	if c.contextMetadata != nil {
+		if headers := c.contextHeaders(ctx); len(headers) > 0 {
+			msg = withHeaderDefaults(msg, headers)
+		}
+	}
+	if len(c.defaultHeaders) > 0 {
+		msg = withHeaderDefaults(msg, c.defaultHeaders)
+	}
+
+	var suppressed []string
+	if c.suppressed != nil {
+		msg, suppressed = c.withoutSuppressed(msg)
+		if len(suppressed) > 0 && len(msg.To)+len(msg.CC)+len(msg.BCC) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrAllRecipientsSuppressed, strings.Join(suppressed, ", "))
+		}
+	}
+
+	if !c.skipValidation {
+		validate := msg.validate
+		if c.strictValidation {
+			validate = msg.validateStrict
+		}
+		if err := validate(c.maxRecipients); err != nil {
+			return nil, fmt.Errorf("message validation failed: %w", err)
+		}
+		if err := c.checkAttachmentSizes(msg); err != nil {
+			return nil, fmt.Errorf("message validation failed: %w", err)
+		}
+		if c.htmlValidation {
+			if err := checkHTML(msg.HTMLBody); err != nil {
+				return nil, fmt.Errorf("message validation failed: %w", err)
+			}
+		}
+	}
+
+	if c.senderDomains != nil {
+		if err := c.checkSenderDomain(msg.Sender); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.requestTimeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+			defer cancel()
+		}
 	}
 
-	payload, err := json.Marshal(msg)
+	payload, err := c.encodeMessage(msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal message: %w", err)
 	}
+	defer func() { payload.release() }()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/send", bytes.NewReader(payload))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if c.compress && payload.streamed() {
+		// Streamed attachments are large by nature and compressed as they are written
+		payload.gzip = true
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Content-Encoding", "gzip")
+	} else if c.compress && len(payload.bytes()) > compressionThreshold {
+		compressed, err := gzipPayload(payload.bytes())
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress message: %w", err)
+		}
+		payload.release()
+		payload = bytesPayload(compressed)
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Content-Encoding", "gzip")
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.apiKey)
+	if c.testMode {
+		return c.testSend(ctx, msg, suppressed)
+	}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	if c.breaker != nil && !c.breaker.allow(c.now()) {
+		return nil, ErrCircuitOpen
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	resp, body, err := c.doRequestWithRetry(ctx, payload, header)
+	if c.breaker != nil {
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up; this says nothing about the API's health
+			c.breaker.release()
+		} else {
+			c.breaker.record(c.now(), isTransient(resp, err))
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, err
 	}
 
 	// Fehlerbehandlung für 4xx und 5xx
@@ -104,11 +412,205 @@ func (c *Client) Send(ctx context.Context, msg *Message) (*SendResponse, error)
 		return nil, parseErrorResponse(resp.StatusCode, body)
 	}
 
+	if err := checkJSONResponse(resp.StatusCode, resp.Header.Get("Content-Type"), body); err != nil {
+		return nil, err
+	}
+
 	var sendResp SendResponse
-	if err := json.Unmarshal(body, &sendResp.Recipients); err != nil {
+	if len(bytes.TrimSpace(body)) == 0 {
+		// A success without body, e.g. 204 No Content, carries no per-recipient results
+		sendResp.Recipients = map[string][]interface{}{}
+	} else if err := json.Unmarshal(body, &sendResp.Recipients); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	sendResp.StatusCode = resp.StatusCode
+	sendResp.Header = resp.Header
+	sendResp.Suppressed = suppressed
+	if c.failOnPartialFailure {
+		if err := sendResp.Error(); err != nil {
+			return &sendResp, err
+		}
+	}
 	return &sendResp, nil
 }
+
+// checkAttachmentSizes returns an error for the first attachment whose decoded size
+// exceeds the limit configured with WithMaxAttachmentSize.
+func (c *Client) checkAttachmentSizes(msg *Message) error {
+	if c.maxAttachmentSize <= 0 {
+		return nil
+	}
+	for i, a := range msg.Attachments {
+		if size := a.Size(); size > c.maxAttachmentSize {
+			return invalidf(fmt.Sprintf("attachments[%d]", i), "attachment %d: %s is %d bytes, maximum is %d",
+				i, a.Filename, size, c.maxAttachmentSize)
+		}
+	}
+	for j, src := range msg.sources {
+		i := len(msg.Attachments) + j
+		if size := src.Size(); size > c.maxAttachmentSize {
+			return invalidf(fmt.Sprintf("attachments[%d]", i), "attachment %d: %s is %d bytes, maximum is %d",
+				i, src.Filename(), size, c.maxAttachmentSize)
+		}
+	}
+	return nil
+}
+
+// withHeaderDefaults returns a copy of msg with the given headers prepended. Headers
+// whose name is already set on the message are skipped, so message-level headers take
+// precedence.
+func withHeaderDefaults(msg *Message, defaults []Header) *Message {
+	merged := msg.Clone()
+	merged.Headers = make([]Header, 0, len(defaults)+len(msg.Headers))
+	for _, h := range defaults {
+		if _, ok := msg.GetHeader(h.Header); !ok {
+			merged.Headers = append(merged.Headers, h)
+		}
+	}
+	merged.Headers = append(merged.Headers, msg.Headers...)
+	return merged
+}
+
+// contextHeaders returns the metadata extracted from ctx by the function configured
+// with WithContextMetadata as headers, sorted by name.
+func (c *Client) contextHeaders(ctx context.Context) []Header {
+	metadata := c.contextMetadata(ctx)
+	headers := make([]Header, 0, len(metadata))
+	for name, value := range metadata {
+		headers = append(headers, Header{Header: name, Value: value})
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Header < headers[j].Header
+	})
+	return headers
+}
+
+// Ping verifies that the client's credentials are accepted by the Sendamatic API.
+// The API offers no dedicated health endpoint, so Ping posts an intentionally empty
+// message to the send endpoint: authentication is checked before the payload is
+// validated, so a validation error (400 or 422) means the credentials are valid and
+// nothing is sent. Ping returns nil on success, an *APIError if the API rejects the
+// credentials (401 or 403) or fails otherwise, and a wrapped error on network failures.
+func (c *Client) Ping(ctx context.Context) error {
+	resp, body, err := c.doRequest(ctx, bytesPayload([]byte("{}")), nil)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode < 400:
+		return nil
+	case resp.StatusCode == http.StatusBadRequest, resp.StatusCode == http.StatusUnprocessableEntity:
+		return nil
+	}
+	return parseErrorResponse(resp.StatusCode, body)
+}
+
+// testModeMessageID is the message ID reported for every recipient in test mode.
+const testModeMessageID = "test-mode"
+
+// testSend completes a send in test mode: instead of submitting msg, it verifies the
+// credentials like Ping and reports every recipient as accepted.
+func (c *Client) testSend(ctx context.Context, msg *Message, suppressed []string) (*SendResponse, error) {
+	if err := c.Ping(ctx); err != nil {
+		return nil, err
+	}
+	resp := &SendResponse{
+		StatusCode: StatusAccepted,
+		Recipients: make(map[string][]interface{}),
+		Suppressed: suppressed,
+		TestMode:   true,
+	}
+	for _, list := range [][]string{msg.To, msg.CC, msg.BCC} {
+		for _, email := range list {
+			resp.Recipients[email] = []interface{}{float64(StatusAccepted), testModeMessageID}
+		}
+	}
+	return resp, nil
+}
+
+// doRequestWithRetry performs doRequest and repeats it for as long as the configured
+// retry policy asks for it. Without a retry policy, exactly one attempt is made. A
+// final *TransportError reports the total number of attempts.
+func (c *Client) doRequestWithRetry(ctx context.Context, payload *payload, header http.Header) (*http.Response, []byte, error) {
+	for attempt := 1; ; attempt++ {
+		resp, body, err := c.doRequest(ctx, payload, header)
+		if c.retryPolicy == nil || ctx.Err() != nil {
+			return resp, body, withAttempts(err, attempt)
+		}
+
+		retry, delay := c.retryPolicy(attempt, resp, err)
+		if !retry {
+			return resp, body, withAttempts(err, attempt)
+		}
+
+		delay = applyJitter(delay, c.jitter, rand.Int64N)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, nil, &TransportError{Attempts: attempt, Err: fmt.Errorf("retry aborted: %w", err)}
+		}
+	}
+}
+
+// doRequest posts the payload to the send endpoint with authentication headers and
+// the given extra headers, and returns the response together with its body, which is read up to the configured
+// size limit. The response body is closed before returning.
+func (c *Client) doRequest(ctx context.Context, payload *payload, header http.Header) (*http.Response, []byte, error) {
+	if c.baseURLErr != nil {
+		return nil, nil, c.baseURLErr
+	}
+	if c.proxyErr != nil {
+		return nil, nil, c.proxyErr
+	}
+
+	reqBody := payload.newBody()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/send", reqBody)
+	if err != nil {
+		reqBody.Close()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = payload.length()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return payload.newBody(), nil
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(c.authHeader, strings.Replace(c.authFormat, "%s", c.apiKey, 1))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, &TransportError{Attempts: 1, Err: fmt.Errorf("request failed: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, nil, &TransportError{Attempts: 1, Err: fmt.Errorf("failed to read response: %w", err)}
+	}
+	if int64(len(body)) > c.maxResponseBytes {
+		return nil, nil, fmt.Errorf("response body exceeds limit of %d bytes", c.maxResponseBytes)
+	}
+
+	if c.responseInterceptor != nil {
+		c.responseInterceptor(resp.StatusCode, bytes.Clone(body))
+	}
+
+	return resp, body, nil
+}
+
+// gzipPayload compresses the given payload using gzip.
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
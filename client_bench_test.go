@@ -0,0 +1,104 @@
+package sendamatic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func benchmarkParallelSend(b *testing.B, opts ...Option) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", append([]Option{WithBaseURL(server.URL)}, opts...)...)
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.Send(context.Background(), msg); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkSend_DefaultPool(b *testing.B) {
+	// Use a private copy of the default transport so the benchmarks don't share connections
+	benchmarkParallelSend(b, WithTransport(http.DefaultTransport.(*http.Transport).Clone()))
+}
+
+func BenchmarkSend_TunedPool(b *testing.B) {
+	benchmarkParallelSend(b, WithConnectionPoolTuning(256, 256, 90*time.Second))
+}
+
+func BenchmarkSend_Allocs(b *testing.B) {
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Order confirmation").
+		SetTextBody(strings.Repeat("Thank you for your order. ", 40)).
+		SetHTMLBody("<p>" + strings.Repeat("Thank you for your order. ", 40) + "</p>")
+	benchmarkSequentialSend(b, msg)
+}
+
+func BenchmarkSend_AllocsWithAttachment(b *testing.B) {
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Invoice").
+		SetTextBody("Please find the invoice attached.").
+		AttachFile("invoice.pdf", "application/pdf", make([]byte, 200<<10))
+	benchmarkSequentialSend(b, msg)
+}
+
+func benchmarkSequentialSend(b *testing.B, msg *Message) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.Send(context.Background(), msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeRecipients(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		body string
+	}{
+		{"single", `{"recipient@example.com": [200, "msg-12345"]}`},
+		{"multiple", `{"a@example.com": [200, "msg-1"], "b@example.com": [550, "msg-2"]}`},
+	} {
+		body := []byte(bench.body)
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var recipients map[string][]interface{}
+				if err := json.Unmarshal(body, &recipients); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,13 +1,19 @@
 package sendamatic
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -37,6 +43,71 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(EnvUser, "env-user")
+	t.Setenv(EnvPassword, "env-pass")
+	t.Setenv(EnvBaseURL, "https://env.api.url")
+
+	client, err := NewClientFromEnv(WithTimeout(5 * time.Second))
+	if err != nil {
+		t.Fatalf("NewClientFromEnv() error = %v", err)
+	}
+	if client.apiKey != "env-user-env-pass" {
+		t.Errorf("apiKey = %q, want %q", client.apiKey, "env-user-env-pass")
+	}
+	if client.baseURL != "https://env.api.url" {
+		t.Errorf("baseURL = %q, want %q", client.baseURL, "https://env.api.url")
+	}
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("httpClient.Timeout = %v, want 5s", client.httpClient.Timeout)
+	}
+
+	// Explicit options take precedence over the environment
+	client, _ = NewClientFromEnv(WithBaseURL("https://option.api.url"))
+	if client.baseURL != "https://option.api.url" {
+		t.Errorf("baseURL = %q, want %q", client.baseURL, "https://option.api.url")
+	}
+}
+
+func TestNewClientFromEnv_Missing(t *testing.T) {
+	t.Setenv(EnvUser, "")
+	t.Setenv(EnvPassword, "")
+	t.Setenv(EnvBaseURL, "")
+
+	_, err := NewClientFromEnv()
+	if err == nil {
+		t.Fatal("Expected error for missing environment variables, got nil")
+	}
+	if !strings.Contains(err.Error(), EnvUser) || !strings.Contains(err.Error(), EnvPassword) {
+		t.Errorf("Error = %q, want to name both missing variables", err.Error())
+	}
+
+	t.Setenv(EnvUser, "env-user")
+	client, err := NewClientFromEnv()
+	if err == nil || client != nil {
+		t.Errorf("NewClientFromEnv() = %v, %v, want nil client and error", client, err)
+	}
+}
+
+func TestClient_MaskedAPIKey(t *testing.T) {
+	tests := []struct {
+		userID   string
+		password string
+		want     string
+	}{
+		{"user", "secretpass", "***********pass"},
+		{"a", "b", "***"},
+		{"", "", "*"},
+	}
+
+	for _, tt := range tests {
+		client := NewClient(tt.userID, tt.password)
+		if got := client.MaskedAPIKey(); got != tt.want {
+			t.Errorf("MaskedAPIKey() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
 func TestClient_Send_Success(t *testing.T) {
 	// Create a test server that returns a successful response
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -69,7 +140,7 @@ func TestClient_Send_Success(t *testing.T) {
 		// Send successful response
 		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
-		response := map[string][2]interface{}{
+		response := map[string][]interface{}{
 			"recipient@example.com": {float64(200), "msg-12345"},
 		}
 		json.NewEncoder(w).Encode(response)
@@ -125,6 +196,15 @@ func TestClient_Send_ValidationError(t *testing.T) {
 	}
 }
 
+func TestClient_Send_NilMessage(t *testing.T) {
+	client := NewClient("user", "pass")
+
+	_, err := client.Send(context.Background(), nil)
+	if !errors.Is(err, ErrNilMessage) {
+		t.Errorf("Send(nil) error = %v, want ErrNilMessage", err)
+	}
+}
+
 func TestClient_Send_APIError(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -221,7 +301,7 @@ func TestClient_Send_ContextTimeout(t *testing.T) {
 		t.Fatal("Expected timeout error, got nil")
 	}
 
-	if !errors.Is(err, context.DeadlineExceeded) && !strings.Contains(err.Error(), "context deadline exceeded") {
+	if !errors.Is(err, context.DeadlineExceeded) {
 		t.Errorf("Expected context deadline exceeded error, got: %v", err)
 	}
 }
@@ -253,7 +333,7 @@ func TestClient_Send_ContextCancellation(t *testing.T) {
 		t.Fatal("Expected cancellation error, got nil")
 	}
 
-	if !errors.Is(err, context.Canceled) && !strings.Contains(err.Error(), "context canceled") {
+	if !errors.Is(err, context.Canceled) {
 		t.Errorf("Expected context canceled error, got: %v", err)
 	}
 }
@@ -262,7 +342,7 @@ func TestClient_Send_MultipleRecipients(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Header().Set("Content-Type", "application/json")
-		response := map[string][2]interface{}{
+		response := map[string][]interface{}{
 			"recipient1@example.com": {float64(200), "msg-11111"},
 			"recipient2@example.com": {float64(200), "msg-22222"},
 			"recipient3@example.com": {float64(550), "msg-33333"}, // Failed delivery
@@ -340,6 +420,29 @@ func TestClient_Send_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestClient_Send_HTMLResponse(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body>Proxy authentication required</body></html>"))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	_, err := client.Send(context.Background(), validMessage())
+	if !errors.Is(err, ErrUnexpectedContentType) {
+		t.Fatalf("Send() error = %v, want ErrUnexpectedContentType", err)
+	}
+	if !strings.Contains(err.Error(), "text/html") || !strings.Contains(err.Error(), "Proxy authentication required") {
+		t.Errorf("Error should mention content type and body, got: %v", err)
+	}
+	if accept != "application/json" {
+		t.Errorf("Accept = %q, want %q", accept, "application/json")
+	}
+}
+
 func TestClient_Send_EmptyResponse(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -404,3 +507,708 @@ func TestClient_Send_WithAttachments(t *testing.T) {
 		t.Errorf("Filename = %q, want %q", receivedMsg.Attachments[0].Filename, "test.txt")
 	}
 }
+
+func TestClient_Send_Compression(t *testing.T) {
+	largeBody := strings.Repeat("Lorem ipsum dolor sit amet. ", 100)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ce := r.Header.Get("Content-Encoding"); ce != "gzip" {
+			t.Errorf("Content-Encoding = %q, want gzip", ce)
+		}
+
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("Failed to create gzip reader: %v", err)
+			return
+		}
+		body, err := io.ReadAll(zr)
+		if err != nil {
+			t.Errorf("Failed to decompress body: %v", err)
+			return
+		}
+
+		var msg Message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Errorf("Failed to unmarshal request body: %v", err)
+		}
+		if msg.TextBody != largeBody {
+			t.Error("Decompressed text body does not match")
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithCompression())
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody(largeBody)
+
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestClient_Send_CompressionBelowThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ce := r.Header.Get("Content-Encoding"); ce != "" {
+			t.Errorf("Content-Encoding = %q, want empty for small payload", ce)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithCompression())
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestClient_Send_ResponseTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "` + strings.Repeat("x", 200) + `"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithMaxResponseBytes(64))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	_, err := client.Send(context.Background(), msg)
+	if err == nil {
+		t.Fatal("Expected error for oversized response, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("Error should mention limit, got: %v", err)
+	}
+}
+
+func TestClient_Send_RequestTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRequestTimeout(10*time.Millisecond))
+
+	_, err := client.Send(context.Background(), msg)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline exceeded error, got: %v", err)
+	}
+
+	// An existing deadline on the context is left untouched
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := client.Send(ctx, msg); err != nil {
+		t.Errorf("Send() with own deadline error = %v, want nil", err)
+	}
+}
+
+func TestClient_Ping(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    bool
+	}{
+		{"validation error means valid credentials", 422, `{"error": "Validation failed"}`, false},
+		{"bad request means valid credentials", 400, `{"error": "Invalid request"}`, false},
+		{"success", 200, `{}`, false},
+		{"unauthorized", 401, `{"error": "Invalid API key"}`, true},
+		{"forbidden", 403, `{"error": "Forbidden"}`, true},
+		{"server error", 500, `{"error": "Internal server error"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if apiKey := r.Header.Get("x-api-key"); apiKey != "user-pass" {
+					t.Errorf("x-api-key = %s, want user-pass", apiKey)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+			err := client.Ping(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Error type = %T, want *APIError", err)
+				}
+				if apiErr.StatusCode != tt.statusCode {
+					t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.statusCode)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Send_TestMode(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "{}" {
+			t.Errorf("request body = %s, want the empty credential check", body)
+		}
+		if r.Header.Get("x-api-key") != "user-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "Invalid API key"}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"error": "Validation failed"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithTestMode())
+	resp, err := client.Send(context.Background(), validMessage().AddCC("cc@example.com"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !resp.TestMode || resp.StatusCode != StatusAccepted || resp.TotalRecipients() != 2 {
+		t.Errorf("Send() = %+v, want a test mode response for 2 recipients", resp)
+	}
+	if id, ok := resp.GetMessageID("cc@example.com"); !ok || id != "test-mode" {
+		t.Errorf("GetMessageID() = %q, %v, want %q, true", id, ok, "test-mode")
+	}
+
+	if _, err := client.Send(context.Background(), NewMessage()); err == nil {
+		t.Error("Send() error = nil, want validation error")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+
+	client = NewClient("user", "wrong", WithBaseURL(server.URL), WithTestMode())
+	var apiErr *APIError
+	if _, err := client.Send(context.Background(), validMessage()); !errors.As(err, &apiErr) || apiErr.StatusCode != 401 {
+		t.Errorf("Send() error = %v, want *APIError with status 401", err)
+	}
+}
+
+func TestClient_Ping_NetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	err := client.Ping(context.Background())
+	if err == nil {
+		t.Fatal("Expected network error, got nil")
+	}
+	if !strings.Contains(err.Error(), "request failed") {
+		t.Errorf("Error should mention request failure, got: %v", err)
+	}
+}
+
+func TestClient_Send_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+	if _, err := client.Send(context.Background(), msg); err == nil {
+		t.Error("Expected certificate error without WithInsecureSkipVerify, got nil")
+	}
+
+	client = NewClient("user", "pass", WithBaseURL(server.URL), WithInsecureSkipVerify())
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestClient_Send_ResponseInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    bool
+	}{
+		{"success", 200, `{"recipient@example.com": [200, "msg-12345"]}`, false},
+		{"api error", 400, `{"error": "Invalid request"}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var gotStatus int
+			var gotBody []byte
+			interceptor := func(statusCode int, body []byte) {
+				gotStatus = statusCode
+				gotBody = append([]byte(nil), body...)
+				// Mutations must not affect the library's parsing
+				for i := range body {
+					body[i] = 'x'
+				}
+			}
+
+			client := NewClient("user", "pass", WithBaseURL(server.URL), WithResponseInterceptor(interceptor))
+
+			msg := NewMessage().
+				SetSender("sender@example.com").
+				AddTo("recipient@example.com").
+				SetSubject("Test").
+				SetTextBody("Body")
+
+			resp, err := client.Send(context.Background(), msg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotStatus != tt.statusCode {
+				t.Errorf("Intercepted status = %d, want %d", gotStatus, tt.statusCode)
+			}
+			if string(gotBody) != tt.body {
+				t.Errorf("Intercepted body = %q, want %q", gotBody, tt.body)
+			}
+			if !tt.wantErr {
+				if msgID, _ := resp.GetMessageID("recipient@example.com"); msgID != "msg-12345" {
+					t.Errorf("MessageID = %q, want %q", msgID, "msg-12345")
+				}
+			}
+		})
+	}
+}
+
+func TestClient_SendWithIdempotencyKey(t *testing.T) {
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(1, time.Millisecond))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	if _, err := client.SendWithIdempotencyKey(context.Background(), msg, "order-42-confirmation"); err != nil {
+		t.Fatalf("SendWithIdempotencyKey() error = %v, want nil", err)
+	}
+
+	if len(keys) != 2 {
+		t.Fatalf("Server calls = %d, want 2", len(keys))
+	}
+	for i, key := range keys {
+		if key != "order-42-confirmation" {
+			t.Errorf("Attempt %d Idempotency-Key = %q, want %q", i+1, key, "order-42-confirmation")
+		}
+	}
+}
+
+func TestClient_Send_DefaultHeaders(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithDefaultHeaders([]Header{
+			{Header: "X-App", Value: "shop"},
+			{Header: "X-Environment", Value: "production"},
+		}),
+	)
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body").
+		AddHeader("x-environment", "staging")
+
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+
+	want := []Header{
+		{Header: "X-App", Value: "shop"},
+		{Header: "x-environment", Value: "staging"},
+	}
+	if len(received.Headers) != len(want) {
+		t.Fatalf("Received headers = %+v, want %+v", received.Headers, want)
+	}
+	for i := range want {
+		if received.Headers[i] != want[i] {
+			t.Errorf("Headers[%d] = %+v, want %+v", i, received.Headers[i], want[i])
+		}
+	}
+
+	if len(msg.Headers) != 1 {
+		t.Errorf("Caller's message was modified: %+v", msg.Headers)
+	}
+}
+
+func TestClient_Send_ContextMetadata(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	type campaignKey struct{}
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithDefaultHeaders([]Header{
+			{Header: "X-Campaign-ID", Value: "default"},
+			{Header: "X-App", Value: "shop"},
+		}),
+		WithContextMetadata(func(ctx context.Context) map[string]string {
+			id, ok := ctx.Value(campaignKey{}).(string)
+			if !ok {
+				return nil
+			}
+			return map[string]string{"X-Tenant": "acme", "X-Campaign-ID": id, "X-Priority": "context"}
+		}),
+	)
+
+	msg := validMessage().AddHeader("x-priority", "message")
+	ctx := context.WithValue(context.Background(), campaignKey{}, "spring-sale")
+	if _, err := client.Send(ctx, msg); err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+
+	want := []Header{
+		{Header: "X-App", Value: "shop"},
+		{Header: "X-Campaign-ID", Value: "spring-sale"},
+		{Header: "X-Tenant", Value: "acme"},
+		{Header: "x-priority", Value: "message"},
+	}
+	if !reflect.DeepEqual(received.Headers, want) {
+		t.Errorf("Received headers = %+v, want %+v", received.Headers, want)
+	}
+	if len(msg.Headers) != 1 {
+		t.Errorf("Caller's message was modified: %+v", msg.Headers)
+	}
+
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+	if v, _ := received.GetHeader("X-Campaign-ID"); v != "default" {
+		t.Errorf("X-Campaign-ID without context metadata = %q, want %q", v, "default")
+	}
+}
+
+func TestClient_Send_ResponseHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	resp, err := client.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+
+	if got := resp.Header.Get("X-RateLimit-Remaining"); got != "42" {
+		t.Errorf("X-RateLimit-Remaining = %q, want %q", got, "42")
+	}
+	if got := resp.Header.Get("Retry-After"); got != "10" {
+		t.Errorf("Retry-After = %q, want %q", got, "10")
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if strings.Contains(string(data), "RateLimit") {
+		t.Errorf("Marshaled response = %s, must not contain headers", data)
+	}
+}
+
+func TestClient_Send_Concurrent(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Failed to create gzip reader: %v", err)
+				return
+			}
+			body = zr
+		}
+		var msg Message
+		json.NewDecoder(body).Decode(&msg)
+
+		response := map[string][]interface{}{}
+		for _, email := range msg.To {
+			response[email] = []interface{}{float64(200), "msg-" + email}
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithCompression(),
+		WithRetry(1, time.Millisecond),
+		WithDefaultHeaders([]Header{{Header: "X-App", Value: "test"}}),
+		WithMetrics(&concurrentMetrics{}),
+	)
+
+	// A shared message is sent from all goroutines to verify Send does not mutate it
+	shared := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("shared@example.com").
+		SetSubject("Test").
+		SetTextBody(strings.Repeat("Body ", 500))
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			own := shared.Clone()
+			own.To = []string{fmt.Sprintf("user%d@example.com", i)}
+
+			for _, msg := range []*Message{shared, own} {
+				resp, err := client.SendWithIdempotencyKey(context.Background(), msg, fmt.Sprintf("key-%d", i))
+				if err != nil {
+					t.Errorf("Send() error = %v", err)
+					return
+				}
+				if _, ok := resp.GetStatus(msg.To[0]); !ok {
+					t.Errorf("Response missing %s", msg.To[0])
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 2*workers {
+		t.Errorf("Server calls = %d, want %d", calls, 2*workers)
+	}
+	if len(shared.Headers) != 0 {
+		t.Errorf("Shared message headers = %+v, want none", shared.Headers)
+	}
+}
+
+// concurrentMetrics is a Metrics implementation that is safe for concurrent use.
+type concurrentMetrics struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (m *concurrentMetrics) ObserveSend(time.Duration, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.count++
+}
+
+func TestClient_Send_InvalidBaseURL(t *testing.T) {
+	client := NewClient("user", "pass", WithBaseURL("host"))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	_, err := client.Send(context.Background(), msg)
+	if err == nil {
+		t.Fatal("Expected error for invalid base URL, got nil")
+	}
+	if !strings.Contains(err.Error(), "invalid base URL") {
+		t.Errorf("Error = %q, want to mention invalid base URL", err.Error())
+	}
+
+	if err := client.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid base URL") {
+		t.Errorf("Ping() error = %v, want invalid base URL error", err)
+	}
+}
+
+func TestClient_Send_TrailingSlashBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/send" {
+			t.Errorf("Path = %s, want /send", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL+"/"))
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+}
+
+func TestClient_Send_NetworkErrorUnwrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(url))
+	_, err := client.Send(context.Background(), validMessage())
+	if err == nil {
+		t.Fatal("Send() error = nil, want network error")
+	}
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) {
+		t.Errorf("errors.As(err, net.Error) = false, want true (err: %v)", err)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Errorf("errors.As(err, *net.OpError) = false, want true (err: %v)", err)
+	}
+}
+
+func TestClient_Send_RetryDeadlineUnwrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(5, time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Send(ctx, validMessage())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want context.DeadlineExceeded in chain", err)
+	}
+}
+
+func TestClient_Send_EmptySuccessBody(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusNoContent} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			client := NewClient("user", "pass", WithBaseURL(server.URL))
+			resp, err := client.Send(context.Background(), validMessage())
+			if err != nil {
+				t.Fatalf("Send() error = %v, want nil", err)
+			}
+			if resp.StatusCode != status {
+				t.Errorf("StatusCode = %d, want %d", resp.StatusCode, status)
+			}
+			if resp.Recipients == nil || len(resp.Recipients) != 0 {
+				t.Errorf("Recipients = %v, want empty map", resp.Recipients)
+			}
+		})
+	}
+}
+
+type closeTrackingTransport struct {
+	http.RoundTripper
+	closed atomic.Int32
+}
+
+func (t *closeTrackingTransport) CloseIdleConnections() {
+	t.closed.Add(1)
+}
+
+func TestClient_Close(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	transport := &closeTrackingTransport{RoundTripper: http.DefaultTransport.(*http.Transport).Clone()}
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithTransport(transport))
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Close() error = %v, want nil", err)
+	}
+	if got := transport.closed.Load(); got != 1 {
+		t.Errorf("CloseIdleConnections calls = %d, want 1", got)
+	}
+
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Errorf("Send() after Close error = %v, want nil", err)
+	}
+
+	var _ io.Closer = client
+	if err := NewClient("user", "pass").Close(); err != nil {
+		t.Errorf("Close() on default client error = %v, want nil", err)
+	}
+}
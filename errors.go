@@ -1,10 +1,49 @@
 package sendamatic
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
+	"strings"
 )
 
+// ErrNilMessage is returned by Send when it is called with a nil *Message.
+var ErrNilMessage = errors.New("sendamatic: message is nil")
+
+// ErrUnexpectedContentType is returned by Send when a successful response is an HTML
+// page instead of JSON, typically because a proxy or captive portal answered instead
+// of the API. The wrapping error includes the content type and the start of the body.
+var ErrUnexpectedContentType = errors.New("sendamatic: unexpected response content type")
+
+// maxBodySnippet is the maximum number of body bytes quoted in content type errors.
+const maxBodySnippet = 100
+
+// checkJSONResponse checks that a successful response body is not an HTML page before
+// it is decoded as JSON. Bodies declared as JSON, or without a content type, are
+// accepted. Other content types are rejected only if they declare HTML or the body
+// starts with markup, so that servers that mislabel JSON, e.g. as text/plain, keep
+// working and other malformed bodies are reported by the JSON decoder.
+func checkJSONResponse(statusCode int, contentType string, body []byte) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	trimmed := bytes.TrimSpace(body)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" && !bytes.HasPrefix(trimmed, []byte("<")) {
+		return nil
+	}
+	if len(trimmed) > maxBodySnippet {
+		trimmed = trimmed[:maxBodySnippet]
+	}
+	return fmt.Errorf("%w %q (status %d), possibly from a proxy: %q",
+		ErrUnexpectedContentType, contentType, statusCode, trimmed)
+}
+
 // APIError represents an error response from the Sendamatic API.
 // It includes the HTTP status code, error message, and optional additional context
 // such as validation errors, JSON path information, and SMTP codes.
@@ -15,6 +54,10 @@ type APIError struct {
 	JSONPath         string `json:"json_path,omitempty"`
 	Sender           string `json:"sender,omitempty"`
 	SMTPCode         int    `json:"smtp_code,omitempty"`
+
+	// Cause holds the underlying error that occurred while interpreting the error
+	// response, such as the decoding error when the body was not valid JSON.
+	Cause error `json:"-"`
 }
 
 // Error implements the error interface and returns a formatted error message.
@@ -27,8 +70,14 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("sendamatic api error (status %d): %s", e.StatusCode, e.Message)
 }
 
+// Unwrap returns the underlying cause, if any, for use with errors.Is and errors.As.
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
 // parseErrorResponse attempts to parse an API error response body into an APIError.
-// If the body cannot be parsed as JSON, it uses the raw body as the error message.
+// If the body cannot be parsed as JSON, it uses the raw body as the error message
+// and records the decoding error as the cause.
 func parseErrorResponse(statusCode int, body []byte) error {
 	var apiErr APIError
 	apiErr.StatusCode = statusCode
@@ -36,7 +85,123 @@ func parseErrorResponse(statusCode int, body []byte) error {
 	if err := json.Unmarshal(body, &apiErr); err != nil {
 		// Fallback, falls JSON nicht parsebar ist
 		apiErr.Message = string(body)
+		apiErr.Cause = err
 	}
 
 	return &apiErr
 }
+
+// ValidationError describes why a message failed validation. Field names the offending
+// part of the message by its JSON field name, such as "sender", "to", "subject" or
+// "charset", with an index for list entries, such as "attachments[2]", "headers[0]"
+// or "tags[1]". A missing body is reported for "text_body". Callers can switch on
+// Field to map errors to form fields.
+//
+// Reason is the human-readable description, which Error returns unchanged; it names
+// the field itself, e.g. "sender is required". Err holds the underlying error, such as
+// an address parsing error, if there is one.
+type ValidationError struct {
+	Field  string
+	Reason string
+	Err    error
+}
+
+// Error implements the error interface and returns the reason.
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// Unwrap returns the underlying error, if any, for use with errors.Is and errors.As.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// invalidf returns a *ValidationError for field whose reason is formatted as by
+// fmt.Errorf. An error wrapped with %w becomes the ValidationError's Err.
+func invalidf(field, format string, args ...any) *ValidationError {
+	err := fmt.Errorf(format, args...)
+	return &ValidationError{Field: field, Reason: err.Error(), Err: errors.Unwrap(err)}
+}
+
+// TransportError is returned by Send when no usable response was received from the
+// API, because every attempt failed on the network or while reading the response, or
+// because waiting for a retry was aborted. Attempts is the number of requests made,
+// including retries configured with WithRetry or WithRetryPolicy, which helps to
+// diagnose flaky networks. Err is the last failure and can be inspected with
+// errors.Is and errors.As, e.g. for context.DeadlineExceeded.
+type TransportError struct {
+	Attempts int
+	Err      error
+}
+
+// Error implements the error interface and returns the last failure, followed by the
+// number of attempts if there was more than one.
+func (e *TransportError) Error() string {
+	if e.Attempts > 1 {
+		return fmt.Sprintf("%v (after %d attempts)", e.Err, e.Attempts)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the last failure for use with errors.Is and errors.As.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// withAttempts records the total number of attempts in a *TransportError. Other
+// errors are returned unchanged.
+func withAttempts(err error, attempts int) error {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		transportErr.Attempts = attempts
+	}
+	return err
+}
+
+// RecipientError describes a delivery failure for a single recipient of an
+// otherwise successful send request. StatusCode holds the SMTP-style status
+// reported by the API, or 0 if the recipient was missing from the response.
+type RecipientError struct {
+	Email      string
+	StatusCode int
+	MessageID  string
+}
+
+// Error implements the error interface and returns a formatted error message.
+func (e *RecipientError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("sendamatic recipient error: no delivery status for %s", e.Email)
+	}
+	return fmt.Sprintf("sendamatic recipient error: %s rejected with status %d", e.Email, e.StatusCode)
+}
+
+// RecipientErrors aggregates the delivery failures of all rejected recipients of a
+// send request. It is returned by SendResponse.Error and is distinct from APIError,
+// which describes a failure of the request as a whole.
+type RecipientErrors []*RecipientError
+
+// Error implements the error interface and lists each failed recipient with its status.
+func (e RecipientErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, re := range e {
+		if re.StatusCode == 0 {
+			parts[i] = fmt.Sprintf("%s (no status)", re.Email)
+		} else {
+			parts[i] = fmt.Sprintf("%s (%d)", re.Email, re.StatusCode)
+		}
+	}
+	noun := "recipients"
+	if len(e) == 1 {
+		noun = "recipient"
+	}
+	return fmt.Sprintf("%d %s failed: %s", len(e), noun, strings.Join(parts, ", "))
+}
+
+// Unwrap returns the individual recipient errors for use with errors.Is and errors.As.
+func (e RecipientErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, re := range e {
+		errs[i] = re
+	}
+	return errs
+}
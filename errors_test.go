@@ -2,6 +2,7 @@ package sendamatic
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -147,6 +148,11 @@ func TestParseErrorResponse_InvalidJSON(t *testing.T) {
 	if apiErr.Message != string(body) {
 		t.Errorf("Message = %q, want %q", apiErr.Message, string(body))
 	}
+
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("errors.As(err, *json.SyntaxError) = false, want true (cause: %v)", apiErr.Cause)
+	}
 }
 
 func TestParseErrorResponse_EmptyBody(t *testing.T) {
@@ -227,3 +233,84 @@ func TestAPIError_JSONRoundtrip(t *testing.T) {
 		t.Errorf("JSONPath = %q, want %q", decoded.JSONPath, original.JSONPath)
 	}
 }
+
+func TestCheckJSONResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantErr     bool
+	}{
+		{"json", "application/json", `{"a@example.com": [200, "msg-1"]}`, false},
+		{"json with charset", "application/json; charset=utf-8", `{}`, false},
+		{"json suffix", "application/problem+json", `{}`, false},
+		{"no content type", "", `{}`, false},
+		{"mislabelled json", "text/plain; charset=utf-8", `{}`, false},
+		{"plain text", "text/plain", `not valid json`, false},
+		{"html", "text/html", `<html></html>`, true},
+		{"html without markup", "text/html", `Bad Gateway`, true},
+		{"markup as text", "text/plain", `  <!DOCTYPE html>`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkJSONResponse(200, tt.contentType, []byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkJSONResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       *Message
+		strict    bool
+		wantField string
+		wantText  string
+	}{
+		{"no recipients", NewMessage().SetSender("s@example.com").SetSubject("S").SetTextBody("B"), false, "to", "at least one recipient required"},
+		{"no sender", validMessage().SetSender(""), false, "sender", "sender is required"},
+		{"no subject", validMessage().SetSubject(""), false, "subject", "subject is required"},
+		{"no body", validMessage().SetTextBody(""), false, "text_body", "either text_body or html_body is required"},
+		{"charset", validMessage().SetCharset("klingon-1"), false, "charset", "unsupported charset: klingon-1"},
+		{"attachment", validMessage().AttachFile("a.txt", "text/plain", []byte("a")).AttachFile("", "text/plain", []byte("b")), false, "attachments[1]", "attachment 1: filename is required"},
+		{"header", validMessage().AddHeader("X-A", "a").AddHeader("In-Reply-To", "bad"), false, "headers[1]", `invalid message id "bad" in In-Reply-To`},
+		{"tag", validMessage().AddTag("ok").AddTag(" "), false, "tags[1]", "tag 1 is empty"},
+		{"duplicate", validMessage().AddCC("to@example.com").RejectDuplicateRecipients(false), false, "cc", "duplicate recipient: to@example.com"},
+		{"strict address", validMessage().AddBCC("not-an-address"), true, "bcc", `bcc address "not-an-address" is invalid: mail: missing '@' or angle-addr`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if tt.strict {
+				err = tt.msg.ValidateStrict()
+			}
+
+			var valErr *ValidationError
+			if !errors.As(err, &valErr) {
+				t.Fatalf("error = %T %v, want *ValidationError", err, err)
+			}
+			if valErr.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q", valErr.Field, tt.wantField)
+			}
+			if valErr.Error() != tt.wantText || valErr.Reason != tt.wantText {
+				t.Errorf("Error() = %q, want %q", valErr.Error(), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestValidationError_Unwrap(t *testing.T) {
+	err := validMessage().AddHeader("Return-Path", "not-an-address").Validate()
+
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Err == nil {
+		t.Fatalf("error = %v, want *ValidationError with underlying error", err)
+	}
+	if !errors.Is(err, valErr.Err) {
+		t.Error("errors.Is does not reach the underlying error")
+	}
+}
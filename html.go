@@ -0,0 +1,212 @@
+package sendamatic
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlDropBlockRe = regexp.MustCompile(`(?is)<(script|style|head)\b[^>]*>.*?</(script|style|head)\s*>`)
+	htmlCommentRe   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlLinkRe      = regexp.MustCompile(`(?is)<a\b[^>]*?\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))[^>]*>(.*?)</a\s*>`)
+	htmlBreakRe     = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlListItemRe  = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlBlockEndRe  = regexp.MustCompile(`(?i)</(p|div|h[1-6]|tr|table|ul|ol|blockquote|pre|section|article|header|footer)\s*>|<hr\b[^>]*>`)
+	htmlTagRe       = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRunRe      = regexp.MustCompile(`[ \t\f\v\r]+`)
+	blankLinesRe    = regexp.MustCompile(`\n{3,}`)
+)
+
+// GenerateTextFromHTML derives a plain-text TextBody from the HTMLBody if the message
+// has an HTML body but no text body; otherwise the message is left unchanged.
+// Tags are stripped, scripts and styles are removed, line breaks and block elements
+// become newlines, list items are prefixed with "- ", links are rendered as
+// "text (url)" and HTML entities are decoded.
+//
+// The conversion is deliberately simple and meant for typical transactional email
+// markup. Call it explicitly after setting the HTML body.
+// Returns the message for method chaining.
+func (m *Message) GenerateTextFromHTML() *Message {
+	if !m.HasText() && m.HasHTML() {
+		m.TextBody = htmlToText(m.HTMLBody)
+	}
+	return m
+}
+
+// htmlToText converts an HTML document to readable plain text.
+func htmlToText(s string) string {
+	s = htmlDropBlockRe.ReplaceAllString(s, "")
+	s = htmlCommentRe.ReplaceAllString(s, "")
+	// Source newlines are insignificant in HTML
+	s = strings.NewReplacer("\r\n", " ", "\n", " ").Replace(s)
+
+	s = htmlLinkRe.ReplaceAllStringFunc(s, func(link string) string {
+		parts := htmlLinkRe.FindStringSubmatch(link)
+		href := parts[1] + parts[2] + parts[3]
+		text := strings.TrimSpace(htmlTagRe.ReplaceAllString(parts[4], ""))
+		switch {
+		case href == "" || strings.HasPrefix(href, "#"):
+			return text
+		case text == "" || text == href || "mailto:"+text == href:
+			return href
+		}
+		return text + " (" + href + ")"
+	})
+
+	s = htmlBreakRe.ReplaceAllString(s, "\n")
+	s = htmlListItemRe.ReplaceAllString(s, "\n- ")
+	s = htmlBlockEndRe.ReplaceAllString(s, "\n\n")
+	s = htmlTagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRunRe.ReplaceAllString(line, " "))
+	}
+	s = strings.Join(lines, "\n")
+	s = blankLinesRe.ReplaceAllString(s, "\n\n")
+	return strings.TrimSpace(s)
+}
+
+var htmlTagTokenRe = regexp.MustCompile(`^<(/?)([A-Za-z][A-Za-z0-9-]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+
+// htmlVoidElements never have content or an end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true,
+	"img": true, "input": true, "link": true, "meta": true, "param": true,
+	"source": true, "track": true, "wbr": true,
+}
+
+// htmlOptionalEndElements may omit their end tag; they are closed implicitly by the
+// end tag of an enclosing element or the end of the document.
+var htmlOptionalEndElements = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true, "dt": true,
+	"dd": true, "option": true, "optgroup": true, "colgroup": true, "thead": true,
+	"tbody": true, "tfoot": true, "tr": true, "td": true, "th": true,
+	"rb": true, "rt": true, "rp": true,
+}
+
+// htmlRawTextElements contain text that is not parsed for tags.
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// openHTMLTag is an element that has not been closed yet.
+type openHTMLTag struct {
+	name string
+	line int
+}
+
+// checkHTML reports the first well-formedness problem in an HTML body: a raw control
+// character, an unterminated tag or comment, an end tag without matching start tag,
+// or an element that is never closed. Void elements, self-closing tags and elements
+// whose end tag HTML allows to omit, such as p, li and td, are accepted. Tag names
+// are compared case-insensitively. The check is structural only and does not know
+// which elements may appear where.
+func checkHTML(s string) error {
+	if i := strings.IndexFunc(s, isHTMLControlChar); i >= 0 {
+		return invalidf("html_body", "html body: control character %U at line %d", rune(s[i]), lineAt(s, i))
+	}
+
+	var stack []openHTMLTag
+	for i := 0; ; {
+		lt := strings.IndexByte(s[i:], '<')
+		if lt < 0 {
+			break
+		}
+		i += lt
+		rest := s[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			end := strings.Index(rest[len("<!--"):], "-->")
+			if end < 0 {
+				return invalidf("html_body", "html body: unterminated comment at line %d", lineAt(s, i))
+			}
+			i += len("<!--") + end + len("-->")
+			continue
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			end := strings.IndexByte(rest, '>')
+			if end < 0 {
+				return invalidf("html_body", "html body: unterminated declaration at line %d", lineAt(s, i))
+			}
+			i += end + 1
+			continue
+		}
+
+		m := htmlTagTokenRe.FindStringSubmatch(rest)
+		if m == nil {
+			if len(rest) > 1 && (rest[1] == '/' || isASCIILetter(rest[1])) {
+				return invalidf("html_body", "html body: malformed tag at line %d", lineAt(s, i))
+			}
+			// A literal "<" in text, e.g. "a < b"
+			i++
+			continue
+		}
+		line := lineAt(s, i)
+		i += len(m[0])
+		closing, name, attrs := m[1] == "/", strings.ToLower(m[2]), m[3]
+
+		if closing {
+			if htmlVoidElements[name] {
+				continue
+			}
+			j := len(stack) - 1
+			for j >= 0 && stack[j].name != name {
+				j--
+			}
+			if j < 0 {
+				return invalidf("html_body", "html body: unexpected </%s> at line %d", name, line)
+			}
+			for _, open := range stack[j+1:] {
+				if !htmlOptionalEndElements[open.name] {
+					return invalidf("html_body", "html body: <%s> opened at line %d is not closed before </%s> at line %d",
+						open.name, open.line, name, line)
+				}
+			}
+			stack = stack[:j]
+			continue
+		}
+
+		if htmlVoidElements[name] || strings.HasSuffix(strings.TrimSpace(attrs), "/") {
+			continue
+		}
+		if htmlRawTextElements[name] {
+			end := strings.Index(strings.ToLower(s[i:]), "</"+name)
+			if end < 0 {
+				return invalidf("html_body", "html body: unclosed <%s> opened at line %d", name, line)
+			}
+			i += end
+		}
+		stack = append(stack, openHTMLTag{name: name, line: line})
+	}
+
+	for k := len(stack) - 1; k >= 0; k-- {
+		if open := stack[k]; !htmlOptionalEndElements[open.name] {
+			return invalidf("html_body", "html body: unclosed <%s> opened at line %d", open.name, open.line)
+		}
+	}
+	return nil
+}
+
+// isHTMLControlChar reports whether r is a control character that must not appear
+// raw in HTML. Tab, line feed, form feed and carriage return are whitespace.
+func isHTMLControlChar(r rune) bool {
+	switch r {
+	case '\t', '\n', '\f', '\r':
+		return false
+	}
+	return r < 0x20 || r == 0x7f
+}
+
+// isASCIILetter reports whether b is an ASCII letter.
+func isASCIILetter(b byte) bool {
+	return 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// lineAt returns the 1-based line number of byte offset i in s.
+func lineAt(s string, i int) int {
+	return strings.Count(s[:i], "\n") + 1
+}
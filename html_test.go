@@ -0,0 +1,113 @@
+package sendamatic
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "paragraphs and breaks",
+			html: "<p>Hello World</p><p>Line one<br>Line two<br/>Line three</p>",
+			want: "Hello World\n\nLine one\nLine two\nLine three",
+		},
+		{
+			name: "links",
+			html: `<p>Visit <a href="https://example.com/account">your account</a> or <a href='mailto:help@example.com'>help@example.com</a>.</p>`,
+			want: "Visit your account (https://example.com/account) or mailto:help@example.com.",
+		},
+		{
+			name: "lists",
+			html: "<ul><li>First</li><li>Second</li></ul>",
+			want: "- First\n- Second",
+		},
+		{
+			name: "entities and whitespace",
+			html: "<div>  Fish &amp; Chips\n   for&nbsp;&euro;5  </div>",
+			want: "Fish & Chips for €5",
+		},
+		{
+			name: "scripts, styles and comments removed",
+			html: "<html><head><title>T</title><style>p{color:red}</style></head><body><!-- hidden --><script>alert(1)</script><h1>Title</h1>Body</body></html>",
+			want: "Title\n\nBody",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlToText(tt.html); got != tt.want {
+				t.Errorf("htmlToText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTextFromHTML(t *testing.T) {
+	msg := NewMessage().SetHTMLBody("<p>Hello</p>").GenerateTextFromHTML()
+	if msg.TextBody != "Hello" {
+		t.Errorf("TextBody = %q, want %q", msg.TextBody, "Hello")
+	}
+
+	msg = NewMessage().SetHTMLBody("<p>Hello</p>").SetTextBody("Custom").GenerateTextFromHTML()
+	if msg.TextBody != "Custom" {
+		t.Errorf("TextBody = %q, want existing text body to be kept", msg.TextBody)
+	}
+
+	msg = NewMessage().GenerateTextFromHTML()
+	if msg.TextBody != "" {
+		t.Errorf("TextBody = %q, want empty without HTML body", msg.TextBody)
+	}
+}
+
+func TestCheckHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		wantErr string
+	}{
+		{name: "empty", html: ""},
+		{name: "document", html: "<!DOCTYPE html>\n<html><head><title>a<b</title><style>p > a {}</style></head>\n<body><p>Hi<br><img src=\"x.png\" alt='a>b'/></p></body></html>"},
+		{name: "optional end tags", html: "<ul><li>one<li>two</ul><table><tr><td>a<td>b</table><p>text"},
+		{name: "case-insensitive", html: "<DIV>text</div>"},
+		{name: "comment and literal less-than", html: "<!-- <div> -->a < b"},
+		{name: "script content", html: "<script>if (a < b) { x = '</div>'; }</script>"},
+		{name: "unclosed element", html: "<div>\n<table><tr><td>x</td></tr>", wantErr: "html body: unclosed <table> opened at line 2"},
+		{name: "stray end tag", html: "<p>text</span>", wantErr: "html body: unexpected </span> at line 1"},
+		{name: "misnested", html: "<b><i>x</b></i>", wantErr: "html body: <i> opened at line 1 is not closed before </b> at line 1"},
+		{name: "unterminated tag", html: "<div class=\"a\"\ntext", wantErr: "html body: malformed tag at line 1"},
+		{name: "unterminated comment", html: "a\n<!-- comment", wantErr: "html body: unterminated comment at line 2"},
+		{name: "unclosed script", html: "<script>alert(1)", wantErr: "html body: unclosed <script> opened at line 1"},
+		{name: "control character", html: "<p>a\n\x00b</p>", wantErr: "html body: control character U+0000 at line 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkHTML(tt.html)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkHTML() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("checkHTML() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithHTMLValidation(t *testing.T) {
+	msg := validMessage().SetHTMLBody("<div><p>Unclosed")
+
+	client := NewClient("user", "pass", WithBaseURL("http://127.0.0.1:0"), WithHTMLValidation())
+	_, err := client.Send(context.Background(), msg)
+	if err == nil || !strings.HasPrefix(err.Error(), "message validation failed: html body: unclosed <div>") {
+		t.Errorf("Send() error = %v, want html validation error", err)
+	}
+}
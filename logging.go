@@ -0,0 +1,48 @@
+package sendamatic
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// logContextKey maps a context key to the attribute name it is logged under.
+type logContextKey struct {
+	name string
+	key  any
+}
+
+// logSend writes a log record for a finished Send call. The record carries the
+// duration, HTTP status code and number of recipients, followed by the configured
+// context values that are present in ctx. Credentials, addresses and message content
+// are never logged.
+//
+// The record is written with ctx, so handlers that read values from the context
+// themselves see the same context as the Send call.
+func (c *Client) logSend(ctx context.Context, msg *Message, duration time.Duration, statusCode int, err error) {
+	level, text := slog.LevelInfo, "sendamatic: message sent"
+	if err != nil {
+		level, text = slog.LevelWarn, "sendamatic: send failed"
+	}
+	if !c.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 4+len(c.logContextKeys))
+	attrs = append(attrs,
+		slog.Duration("duration", duration),
+		slog.Int("status", statusCode),
+	)
+	if msg != nil {
+		attrs = append(attrs, slog.Int("recipients", len(msg.To)+len(msg.CC)+len(msg.BCC)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	for _, k := range c.logContextKeys {
+		if v := ctx.Value(k.key); v != nil {
+			attrs = append(attrs, slog.Any(k.name, v))
+		}
+	}
+	c.logger.LogAttrs(ctx, level, text, attrs...)
+}
@@ -0,0 +1,113 @@
+package sendamatic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type requestIDKey struct{}
+
+func TestWithLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient("user", "secret-pass",
+		WithBaseURL(server.URL),
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))),
+		WithLogContextKey("request_id", requestIDKey{}),
+		WithLogContextKey("tenant", "unset-key"))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-42")
+	if _, err := client.Send(ctx, validMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log output %q is not a single JSON record: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"level":      "INFO",
+		"msg":        "sendamatic: message sent",
+		"status":     float64(200),
+		"recipients": float64(1),
+		"request_id": "req-42",
+	}
+	for k, v := range want {
+		if record[k] != v {
+			t.Errorf("record[%q] = %v, want %v", k, record[k], v)
+		}
+	}
+	if _, ok := record["tenant"]; ok {
+		t.Error("record contains tenant, want absent context value omitted")
+	}
+	for _, secret := range []string{"secret-pass", "recipient@example.com"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("log output contains %q", secret)
+		}
+	}
+}
+
+func TestWithLogger_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "Unauthorized"}`))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+
+	if _, err := client.Send(context.Background(), validMessage()); err == nil {
+		t.Fatal("Send() error = nil, want error")
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log output %q is not a single JSON record: %v", buf.String(), err)
+	}
+	if record["level"] != "WARN" || record["status"] != float64(401) || record["error"] == nil {
+		t.Errorf("record = %v, want WARN with status 401 and error", record)
+	}
+}
+
+// contextHandler records the request ID found in the context of each record.
+type contextHandler struct {
+	slog.Handler
+	got *string
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.got, _ = ctx.Value(requestIDKey{}).(string)
+	return nil
+}
+
+func TestWithLogger_ContextPassedToHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	var got string
+	handler := contextHandler{Handler: slog.NewTextHandler(&bytes.Buffer{}, nil), got: &got}
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithLogger(slog.New(handler)))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-7")
+	if _, err := client.Send(ctx, validMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got != "req-7" {
+		t.Errorf("handler saw request ID %q, want %q", got, "req-7")
+	}
+}
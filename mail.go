@@ -0,0 +1,191 @@
+package sendamatic
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+)
+
+// mailStructuralHeaders lists headers that FromMailMessage maps to dedicated Message
+// fields or that describe the MIME structure, and therefore are not copied as custom headers.
+var mailStructuralHeaders = map[string]bool{
+	"From":                      true,
+	"To":                        true,
+	"Cc":                        true,
+	"Bcc":                       true,
+	"Subject":                   true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+}
+
+// mailTraceHeaders lists trace, signature and transport headers that belong to the
+// original delivery and are therefore not copied by FromMailMessage. A stale
+// DKIM-Signature, for instance, would fail verification on the new message and break
+// DMARC, and the API sets Date and Message-ID itself.
+var mailTraceHeaders = map[string]bool{
+	"Received":                   true,
+	"Received-Spf":               true,
+	"Return-Path":                true,
+	"Delivered-To":               true,
+	"X-Original-To":              true,
+	"Date":                       true,
+	"Message-Id":                 true,
+	"Dkim-Signature":             true,
+	"Domainkey-Signature":        true,
+	"Authentication-Results":     true,
+	"Arc-Seal":                   true,
+	"Arc-Message-Signature":      true,
+	"Arc-Authentication-Results": true,
+}
+
+// FromMailMessage converts a parsed net/mail.Message into a Message.
+// The From, To, Cc, Bcc and Subject headers are mapped to the corresponding fields,
+// with RFC 2047 encoded display names and subjects decoded. Trace, signature and
+// transport headers of the original delivery, such as Received, DKIM-Signature, Date,
+// Message-ID and Return-Path, are dropped; all other headers are preserved as custom
+// headers in sorted order.
+//
+// Single-part text/plain and text/html bodies are mapped to TextBody and HTMLBody.
+// Multipart bodies are walked recursively: text/plain and text/html parts populate
+// the bodies, while parts with a filename or an attachment disposition are added
+// as attachments. Base64 and quoted-printable transfer encodings are decoded.
+//
+// Example:
+//
+//	raw, _ := mail.ReadMessage(r)
+//	msg, err := sendamatic.FromMailMessage(raw)
+func FromMailMessage(m *mail.Message) (*Message, error) {
+	if m == nil {
+		return nil, errors.New("mail message is nil")
+	}
+
+	msg := NewMessage()
+	if m.Header.Get("From") != "" {
+		from, err := m.Header.AddressList("From")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse From header: %w", err)
+		}
+		switch {
+		case len(from) == 0:
+			// An empty group such as "undisclosed:;" names no sender.
+		case from[0].Name == "":
+			msg.SetSender(from[0].Address)
+		default:
+			msg.SetSender(from[0].String())
+		}
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode subject: %w", err)
+	}
+	msg.SetSubject(subject)
+
+	for _, field := range []struct {
+		name string
+		add  func(string) *Message
+	}{
+		{"To", msg.AddTo},
+		{"Cc", msg.AddCC},
+		{"Bcc", msg.AddBCC},
+	} {
+		if m.Header.Get(field.name) == "" {
+			continue
+		}
+		addrs, err := m.Header.AddressList(field.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s header: %w", field.name, err)
+		}
+		for _, addr := range addrs {
+			if addr.Name == "" {
+				field.add(addr.Address)
+			} else {
+				field.add(addr.String())
+			}
+		}
+	}
+
+	names := make([]string, 0, len(m.Header))
+	for name := range m.Header {
+		key := textproto.CanonicalMIMEHeaderKey(name)
+		if !mailStructuralHeaders[key] && !mailTraceHeaders[key] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range m.Header[name] {
+			msg.AddHeader(name, value)
+		}
+	}
+
+	if err := readMailPart(msg, textproto.MIMEHeader(m.Header), m.Body); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// readMailPart decodes a single MIME entity and stores its content in msg.
+// Multipart entities are processed recursively.
+func readMailPart(msg *Message, header textproto.MIMEHeader, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045: default to text/plain when the Content-Type is missing or invalid
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read multipart body: %w", err)
+			}
+			if err := readMailPart(msg, part.Header, part); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(header.Get("Content-Transfer-Encoding")) {
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read mail body: %w", err)
+	}
+
+	disposition, dispParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispParams["filename"]
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	switch {
+	case disposition == "attachment" || filename != "":
+		msg.AttachFile(filename, mediaType, data)
+	case mediaType == "text/plain" && msg.TextBody == "":
+		msg.SetTextBody(string(data))
+	case mediaType == "text/html" && msg.HTMLBody == "":
+		msg.SetHTMLBody(string(data))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package sendamatic
+
+import (
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestFromMailMessage_PlainText(t *testing.T) {
+	raw := "From: Sender <sender@example.com>\r\n" +
+		"To: a@example.com, B <b@example.com>\r\n" +
+		"Cc: c@example.com\r\n" +
+		"Subject: =?utf-8?q?Gr=C3=BC=C3=9Fe?=\r\n" +
+		"Reply-To: reply@example.com\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"Hello World"
+
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+
+	msg, err := FromMailMessage(m)
+	if err != nil {
+		t.Fatalf("FromMailMessage() error = %v", err)
+	}
+
+	if msg.Sender != `"Sender" <sender@example.com>` {
+		t.Errorf("Sender = %q, want %q", msg.Sender, `"Sender" <sender@example.com>`)
+	}
+	if len(msg.To) != 2 || msg.To[0] != "a@example.com" || msg.To[1] != `"B" <b@example.com>` {
+		t.Errorf("To = %v", msg.To)
+	}
+	if len(msg.CC) != 1 {
+		t.Errorf("CC length = %d, want 1", len(msg.CC))
+	}
+	if msg.Subject != "Grüße" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "Grüße")
+	}
+	if msg.TextBody != "Hello World" {
+		t.Errorf("TextBody = %q, want %q", msg.TextBody, "Hello World")
+	}
+	if len(msg.Headers) != 1 || msg.Headers[0].Header != "Reply-To" {
+		t.Errorf("Headers = %+v, want only Reply-To", msg.Headers)
+	}
+}
+
+func TestFromMailMessage_DropsTraceHeaders(t *testing.T) {
+	raw := "Received: from mx.example.net by mx.example.com\r\n" +
+		"DKIM-Signature: v=1; a=rsa-sha256; d=example.com; b=abc\r\n" +
+		"ARC-Seal: i=1; a=rsa-sha256; b=def\r\n" +
+		"Return-Path: <bounce@example.com>\r\n" +
+		"Date: Mon, 2 Jan 2006 15:04:05 +0000\r\n" +
+		"Message-ID: <old@example.com>\r\n" +
+		"From: =?iso-8859-1?q?J=F6rg?= <joerg@example.com>\r\n" +
+		"To: to@example.com\r\n" +
+		"Subject: Test\r\n" +
+		"X-Campaign: spring\r\n" +
+		"\r\n" +
+		"Body"
+
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+
+	msg, err := FromMailMessage(m)
+	if err != nil {
+		t.Fatalf("FromMailMessage() error = %v", err)
+	}
+
+	if len(msg.Headers) != 1 || msg.Headers[0].Header != "X-Campaign" {
+		t.Errorf("Headers = %+v, want only X-Campaign", msg.Headers)
+	}
+	from, err := mail.ParseAddress(msg.Sender)
+	if err != nil || from.Name != "Jörg" || from.Address != "joerg@example.com" {
+		t.Errorf("Sender = %q, want decoded name Jörg", msg.Sender)
+	}
+
+	m, _ = mail.ReadMessage(strings.NewReader("From: not an address\r\n\r\nBody"))
+	if _, err := FromMailMessage(m); err == nil {
+		t.Error("FromMailMessage() error = nil, want error for invalid From")
+	}
+}
+
+func TestFromMailMessage_Multipart(t *testing.T) {
+	raw := "From: sender@example.com\r\n" +
+		"To: to@example.com\r\n" +
+		"Subject: Multipart\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: multipart/alternative; boundary=inner\r\n" +
+		"\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Hello =C3=BCber\r\n" +
+		"--inner\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		"<p>Hello</p>\r\n" +
+		"--inner--\r\n" +
+		"--outer\r\n" +
+		"Content-Type: application/pdf\r\n" +
+		"Content-Disposition: attachment; filename=\"doc.pdf\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"cGRm\r\n" +
+		"ZGF0YQ==\r\n" +
+		"--outer--\r\n"
+
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+
+	msg, err := FromMailMessage(m)
+	if err != nil {
+		t.Fatalf("FromMailMessage() error = %v", err)
+	}
+
+	if msg.TextBody != "Hello über" {
+		t.Errorf("TextBody = %q, want %q", msg.TextBody, "Hello über")
+	}
+	if msg.HTMLBody != "<p>Hello</p>" {
+		t.Errorf("HTMLBody = %q, want %q", msg.HTMLBody, "<p>Hello</p>")
+	}
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("Attachments length = %d, want 1", len(msg.Attachments))
+	}
+	att := msg.Attachments[0]
+	if att.Filename != "doc.pdf" || att.MimeType != "application/pdf" {
+		t.Errorf("Attachment = %+v", att)
+	}
+	if att.Data != "cGRmZGF0YQ==" {
+		t.Errorf("Attachment data = %q, want %q", att.Data, "cGRmZGF0YQ==")
+	}
+	if len(msg.Headers) != 0 {
+		t.Errorf("Headers = %+v, want none", msg.Headers)
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestFromMailMessage_EmptyFromGroup(t *testing.T) {
+	raw := "From: undisclosed:;\r\n" +
+		"To: to@example.com\r\n" +
+		"Subject: Test\r\n" +
+		"\r\n" +
+		"Body"
+
+	m, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage failed: %v", err)
+	}
+
+	msg, err := FromMailMessage(m)
+	if err != nil {
+		t.Fatalf("FromMailMessage() error = %v", err)
+	}
+	if msg.Sender != "" {
+		t.Errorf("Sender = %q, want empty", msg.Sender)
+	}
+}
+
+func TestFromMailMessage_Nil(t *testing.T) {
+	if _, err := FromMailMessage(nil); err == nil {
+		t.Error("Expected error for nil mail message, got nil")
+	}
+}
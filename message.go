@@ -1,24 +1,61 @@
 package sendamatic
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/url"
 	"os"
+	"path"
+	"sort"
+	"strings"
 )
 
 // Message represents an email message with all its components including recipients,
 // content, headers, and attachments. Messages are constructed using the fluent builder
 // pattern provided by the setter methods.
+//
+// Messages marshal to JSON deterministically: fields appear in the order they are
+// declared below, and Headers and Attachments preserve insertion order. Helpers that
+// accept maps, such as AddHeaders, add entries in sorted key order. This makes the
+// payload suitable for golden-file tests.
 type Message struct {
-	To          []string     `json:"to"`
-	CC          []string     `json:"cc,omitempty"`
-	BCC         []string     `json:"bcc,omitempty"`
-	Sender      string       `json:"sender"`
-	Subject     string       `json:"subject"`
-	TextBody    string       `json:"text_body,omitempty"`
-	HTMLBody    string       `json:"html_body,omitempty"`
-	Headers     []Header     `json:"headers,omitempty"`
-	Attachments []Attachment `json:"attachments,omitempty"`
+	To              []string     `json:"to"`
+	CC              []string     `json:"cc,omitempty"`
+	BCC             []string     `json:"bcc,omitempty"`
+	Sender          string       `json:"sender"`
+	Subject         string       `json:"subject"`
+	TextBody        string       `json:"text_body,omitempty"`
+	HTMLBody        string       `json:"html_body,omitempty"`
+	TextContentType string       `json:"text_content_type,omitempty"` // Defaults to text/plain
+	HTMLContentType string       `json:"html_content_type,omitempty"` // Defaults to text/html
+	AMPBody         string       `json:"amp_body,omitempty"`
+	Charset         string       `json:"charset,omitempty"` // Not in the API documentation; see SetCharset
+	BodyEncoding    BodyEncoding `json:"body_encoding,omitempty"`
+	Headers         []Header     `json:"headers,omitempty"`
+	Attachments     []Attachment `json:"attachments,omitempty"`
+	Category        string       `json:"category,omitempty"`
+	Tags            []string     `json:"tags,omitempty"`
+	Queue           string       `json:"queue,omitempty"`
+
+	// RecipientVariables holds per-recipient merge variables keyed by the bare,
+	// normalized recipient address. The field is not in the API documentation; see
+	// AddToWithVars.
+	RecipientVariables map[string]map[string]interface{} `json:"recipient_variables,omitempty"`
+
+	rejectDuplicates bool
+	stripTags        bool
+	rejectSelfSend   bool
+
+	sources []AttachmentSource // streamed attachments; see AttachSource
 }
 
 // Header represents a custom email header as a name-value pair.
@@ -28,12 +65,41 @@ type Header struct {
 }
 
 // Attachment represents an email attachment with its filename, MIME type, and base64-encoded data.
+// Filenames are transmitted as UTF-8 in the JSON payload, so non-ASCII names such as
+// "Rechnung_Übersicht.pdf" are passed through unchanged.
+//
+// Encoding selects the Content-Transfer-Encoding declared for the attachment in the
+// delivered email. It is independent of Data, which is always base64 in the payload.
+// AttachFile and the other Attach methods set it to BodyEncodingBase64; change it to
+// BodyEncodingQuotedPrintable for receivers that require quoted-printable attachments.
+// In an Attachment built directly, the zero value BodyEncodingAuto omits the field and
+// leaves the choice to the API, as for the message bodies.
 type Attachment struct {
-	Filename string `json:"filename"`
-	Data     string `json:"data"` // Base64-encoded file content
-	MimeType string `json:"mimetype"`
+	Filename string       `json:"filename"`
+	Data     string       `json:"data"` // Base64-encoded file content
+	MimeType string       `json:"mimetype"`
+	Encoding BodyEncoding `json:"encoding,omitempty"`
+}
+
+// Size returns the decoded size of the attachment in bytes, computed from the length of
+// its base64 data without decoding it. Line breaks in wrapped data are not counted.
+func (a Attachment) Size() int64 {
+	data := a.Data
+	if strings.ContainsAny(data, "\r\n") {
+		data = strings.NewReplacer("\r", "", "\n", "").Replace(data)
+	}
+	n := len(data)
+	size := int64(n / 4 * 3)
+	if n >= 4 {
+		size -= int64(len(data[n-2:]) - len(strings.TrimRight(data[n-2:], "=")))
+	}
+	return size
 }
 
+// maxRecipients is the default maximum number of To recipients the API accepts per
+// message. A client can raise or lower it with WithMaxRecipients.
+const maxRecipients = 255
+
 // NewMessage creates and returns a new empty Message with initialized slices for recipients,
 // headers, and attachments. Use the setter methods to populate the message fields.
 func NewMessage() *Message {
@@ -46,6 +112,78 @@ func NewMessage() *Message {
 	}
 }
 
+// NewSimpleMessage creates a message from NewMessage with the sender, a single To
+// recipient, the subject and a plain text body already set, which covers the common
+// case of a one-line send. The result is chainable like any other message, e.g. to add
+// CC recipients or attachments.
+//
+// Example:
+//
+//	msg := sendamatic.NewSimpleMessage("sender@example.com", "recipient@example.com",
+//		"Hello", "This is a test message.").AddCC("cc@example.com")
+func NewSimpleMessage(sender, to, subject, textBody string) *Message {
+	return NewMessage().
+		SetSender(sender).
+		AddTo(to).
+		SetSubject(subject).
+		SetTextBody(textBody)
+}
+
+// Reset clears all fields of the message so that it can be reused like a new message
+// from NewMessage, which avoids allocations when many messages are built in a loop.
+// The backing arrays of the recipient, header, attachment and tag slices are kept
+// and their elements cleared. Reset must not be called while a Send of the message is
+// still in flight, and messages obtained from Clone do not share the reused arrays.
+// Returns the message for method chaining.
+func (m *Message) Reset() *Message {
+	clear(m.Tags[:cap(m.Tags)])
+	*m = Message{
+		To:          emptied(m.To),
+		CC:          emptied(m.CC),
+		BCC:         emptied(m.BCC),
+		Headers:     emptied(m.Headers),
+		Attachments: emptied(m.Attachments),
+		Tags:        m.Tags[:0],
+		sources:     emptied(m.sources),
+	}
+	return m
+}
+
+// emptied clears the elements of s and returns it with length zero, keeping its
+// backing array. A nil slice is replaced by an empty one.
+func emptied[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	clear(s[:cap(s)])
+	return s[:0]
+}
+
+// Clone returns a deep copy of the message. Modifying the copy's recipients, headers,
+// attachments or recipient variables does not affect the original. The variable values
+// themselves are copied as-is, so a value that is a map or slice remains shared.
+func (m *Message) Clone() *Message {
+	c := *m
+	c.To = append([]string{}, m.To...)
+	c.CC = append([]string{}, m.CC...)
+	c.BCC = append([]string{}, m.BCC...)
+	c.Headers = append([]Header{}, m.Headers...)
+	c.Attachments = append([]Attachment{}, m.Attachments...)
+	if m.sources != nil {
+		c.sources = append([]AttachmentSource{}, m.sources...)
+	}
+	if m.Tags != nil {
+		c.Tags = append([]string{}, m.Tags...)
+	}
+	if m.RecipientVariables != nil {
+		c.RecipientVariables = make(map[string]map[string]interface{}, len(m.RecipientVariables))
+		for email, vars := range m.RecipientVariables {
+			c.RecipientVariables[email] = maps.Clone(vars)
+		}
+	}
+	return &c
+}
+
 // AddTo adds a recipient email address to the To field.
 // Returns the message for method chaining.
 func (m *Message) AddTo(email string) *Message {
@@ -53,6 +191,31 @@ func (m *Message) AddTo(email string) *Message {
 	return m
 }
 
+// AddToWithVars adds a recipient to the To field together with merge variables for
+// personalizing the message for that recipient, for example {"first_name": "Jane"}.
+// The variables are sent in the payload as "recipient_variables", an object mapping
+// each recipient's bare address, normalized with NormalizeAddress, to its variables.
+// Calling it again for the same address replaces its variables. Validate checks that
+// every set of variables belongs to a recipient of the message. Returns the message for
+// method chaining.
+//
+// The field is speculative: the Sendamatic API documentation does not list it or a
+// placeholder syntax, and the library substitutes nothing itself. If the API ignores
+// the field, recipients receive the content with its placeholders unreplaced. Verify
+// with a test send before relying on it.
+func (m *Message) AddToWithVars(email string, vars map[string]interface{}) *Message {
+	m.AddTo(email)
+	if m.RecipientVariables == nil {
+		m.RecipientVariables = make(map[string]map[string]interface{})
+	}
+	copied := make(map[string]interface{}, len(vars))
+	for k, v := range vars {
+		copied[k] = v
+	}
+	m.RecipientVariables[NormalizeAddress(email, false)] = copied
+	return m
+}
+
 // AddCC adds a recipient email address to the CC (carbon copy) field.
 // Returns the message for method chaining.
 func (m *Message) AddCC(email string) *Message {
@@ -67,13 +230,168 @@ func (m *Message) AddBCC(email string) *Message {
 	return m
 }
 
+// AddToName adds a To recipient with a display name, formatted as
+// "Name <email>". Names containing commas, quotes or other special characters are
+// quoted and escaped, and non-ASCII names are RFC 2047 encoded, so the result always
+// parses as a single address. An empty name adds the bare address.
+// Returns the message for method chaining.
+func (m *Message) AddToName(name, email string) *Message {
+	return m.AddTo(formatAddress(name, email))
+}
+
+// AddCCName adds a CC recipient with a display name, formatted like AddToName.
+// Returns the message for method chaining.
+func (m *Message) AddCCName(name, email string) *Message {
+	return m.AddCC(formatAddress(name, email))
+}
+
+// AddBCCName adds a BCC recipient with a display name, formatted like AddToName.
+// Returns the message for method chaining.
+func (m *Message) AddBCCName(name, email string) *Message {
+	return m.AddBCC(formatAddress(name, email))
+}
+
+// formatAddress returns email with the given display name in RFC 5322 form.
+func formatAddress(name, email string) string {
+	email = strings.TrimSpace(email)
+	if name = strings.TrimSpace(name); name == "" {
+		return email
+	}
+	return (&mail.Address{Name: name, Address: email}).String()
+}
+
 // SetSender sets the sender email address for the message.
+// The address may be a bare address ("sender@example.com") or include a display name
+// ("Example Shop <sender@example.com>"). It is shown to recipients as the From address.
 // Returns the message for method chaining.
 func (m *Message) SetSender(email string) *Message {
 	m.Sender = email
 	return m
 }
 
+// SetReturnPath sets the envelope sender (Return-Path) that receives bounces, which may
+// differ from the visible sender. The three addresses serve different purposes:
+//   - Sender (SetSender) is the From address recipients see
+//   - Return-Path (SetReturnPath) is where delivery failures and bounces are sent
+//   - Reply-To (AddHeader("Reply-To", ...)) is where recipients' replies are sent
+//
+// Calling it again replaces the previous value; Validate checks the address format.
+// SetEnvelopeFrom is an alias documented with VERP usage in mind.
+// Returns the message for method chaining.
+func (m *Message) SetReturnPath(email string) *Message {
+	m.RemoveHeader("Return-Path")
+	m.AddHeader("Return-Path", "<"+strings.Trim(strings.TrimSpace(email), "<>")+">")
+	return m
+}
+
+// SetEnvelopeFrom sets the envelope sender, the address used in the SMTP MAIL FROM
+// command, independently of the From address in Sender. The API has no dedicated
+// payload field for it and takes the envelope sender from the Return-Path header, so
+// SetEnvelopeFrom is equivalent to SetReturnPath; use whichever name reads better.
+// It differs from the other sender addresses as follows:
+//   - Sender is the visible From address; recipients see it and reply filters use it
+//   - The envelope sender is never shown by mail clients and only receives bounces
+//     and delivery status notifications
+//   - Reply-To is a header that redirects replies typed by humans and has no
+//     effect on bounces
+//
+// For VERP-style bounce routing, encode the recipient into the envelope sender so
+// that a bounce identifies the failed address without parsing the bounce body.
+// Validate rejects addresses that cannot be parsed. Returns the message for method
+// chaining.
+//
+// Example:
+//
+//	msg.AddTo("jane@example.com").
+//		SetEnvelopeFrom("bounces+jane=example.com@mail.example.com")
+func (m *Message) SetEnvelopeFrom(email string) *Message {
+	return m.SetReturnPath(email)
+}
+
+// EnvelopeFrom returns the envelope sender set with SetEnvelopeFrom or SetReturnPath,
+// without angle brackets, or an empty string if none is set.
+func (m *Message) EnvelopeFrom() string {
+	value, _ := m.GetHeader("Return-Path")
+	return strings.Trim(value, "<>")
+}
+
+// SetMessageID sets the Message-ID header, e.g. for threading or for correlating logs
+// with delivered mail. The id has the form "local@domain" and is wrapped in angle
+// brackets unless it already is. Calling it again replaces the previous value;
+// Validate rejects IDs that are not a valid RFC 5322 msg-id. Without a Message-ID,
+// the API generates one. A supplied ID must be globally unique, e.g. a random value
+// at a domain you own. The per-recipient message IDs in SendResponse are the API's own
+// tracking identifiers and may differ from this header; should the API override the
+// header, the delivered mail carries the API's value.
+// Returns the message for method chaining.
+//
+// Example:
+//
+//	msg.SetMessageID(fmt.Sprintf("%s@mail.example.com", orderID))
+func (m *Message) SetMessageID(id string) *Message {
+	m.RemoveHeader("Message-ID")
+	m.AddHeader("Message-ID", bracketMessageID(id))
+	return m
+}
+
+// SetInReplyTo sets the In-Reply-To header to the Message-ID of the message being
+// replied to, so that mail clients thread the reply. The id is wrapped in angle
+// brackets unless it already is. Calling it again replaces the previous value;
+// Validate checks the ID format. Replies should usually also reference the same ID
+// with AddReference. Returns the message for method chaining.
+func (m *Message) SetInReplyTo(messageID string) *Message {
+	m.RemoveHeader("In-Reply-To")
+	m.AddHeader("In-Reply-To", bracketMessageID(messageID))
+	return m
+}
+
+// AddReference appends a Message-ID to the References header, which lists the IDs of
+// all earlier messages in the thread from oldest to newest, separated by spaces. The
+// id is wrapped in angle brackets unless it already is, and the header is created on
+// first use. Validate checks every listed ID. Returns the message for method chaining.
+//
+// Example:
+//
+//	msg.SetInReplyTo(parentID).
+//		AddReference(rootID).
+//		AddReference(parentID)
+func (m *Message) AddReference(messageID string) *Message {
+	references, _ := m.GetHeader("References")
+	m.RemoveHeader("References")
+	m.AddHeader("References", strings.TrimSpace(references+" "+bracketMessageID(messageID)))
+	return m
+}
+
+// bracketMessageID trims id and wraps it in angle brackets unless it already is.
+func bracketMessageID(id string) string {
+	return "<" + strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(id), "<"), ">") + ">"
+}
+
+// validMessageID reports whether id is an RFC 5322 msg-id of the form
+// "<local@domain>" with dot-atom text on both sides of the "@".
+func validMessageID(id string) bool {
+	if len(id) < 2 || id[0] != '<' || id[len(id)-1] != '>' {
+		return false
+	}
+	local, domain, ok := strings.Cut(id[1:len(id)-1], "@")
+	return ok && isDotAtom(local) && isDotAtom(domain)
+}
+
+// isDotAtom reports whether s is RFC 5322 dot-atom text: atoms of printable ASCII
+// characters other than specials, separated by single dots.
+func isDotAtom(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' || strings.Contains(s, "..") {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c > '~' || strings.IndexByte(`()<>[]:;@\,"`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // SetSubject sets the email subject line.
 // Returns the message for method chaining.
 func (m *Message) SetSubject(subject string) *Message {
@@ -81,20 +399,224 @@ func (m *Message) SetSubject(subject string) *Message {
 	return m
 }
 
-// SetTextBody sets the plain text body of the email.
+// SetTextBody sets the plain text body of the email. The body part is sent with the
+// content type text/plain; use SetTextBodyWithType to override the media type.
 // Returns the message for method chaining.
 func (m *Message) SetTextBody(body string) *Message {
 	m.TextBody = body
 	return m
 }
 
-// SetHTMLBody sets the HTML body of the email.
+// SetHTMLBody sets the HTML body of the email. The body part is sent with the content
+// type text/html; use SetHTMLBodyWithType to override the media type.
 // Returns the message for method chaining.
 func (m *Message) SetHTMLBody(body string) *Message {
 	m.HTMLBody = body
 	return m
 }
 
+// SetTextBodyWithType sets the plain text body together with the media type of its body
+// part, e.g. "text/plain; format=flowed", for the unusual cases where text/plain does
+// not fit. The content type is sent to the API as "text_content_type". Validate
+// requires a well-formed text/* media type.
+// Returns the message for method chaining.
+func (m *Message) SetTextBodyWithType(body, contentType string) *Message {
+	m.TextBody = body
+	m.TextContentType = contentType
+	return m
+}
+
+// SetHTMLBodyWithType sets the HTML body together with the media type of its body
+// part, for the unusual cases where text/html does not fit. The content type is sent to
+// the API as "html_content_type". Validate requires a well-formed text/* media type.
+// Returns the message for method chaining.
+func (m *Message) SetHTMLBodyWithType(body, contentType string) *Message {
+	m.HTMLBody = body
+	m.HTMLContentType = contentType
+	return m
+}
+
+// SetAMPBody sets an AMP for Email body, sent as a text/x-amp-html part that clients
+// such as Gmail render as an interactive email. Clients without AMP support show the
+// HTML body instead, so Validate requires an HTML body whenever an AMP body is set.
+// The AMP document is sent to the API as "amp_body" and is not checked for AMP
+// validity. Returns the message for method chaining.
+func (m *Message) SetAMPBody(amp string) *Message {
+	m.AMPBody = amp
+	return m
+}
+
+// HasAMP reports whether the message has an AMP body.
+func (m *Message) HasAMP() bool {
+	return m.AMPBody != ""
+}
+
+// HasText reports whether the message has a plain-text body.
+func (m *Message) HasText() bool {
+	return m.TextBody != ""
+}
+
+// HasHTML reports whether the message has an HTML body.
+func (m *Message) HasHTML() bool {
+	return m.HTMLBody != ""
+}
+
+// BodyKinds returns the MIME types of the bodies the message carries, in the order of
+// a multipart/alternative email: "text/plain", then "text/x-amp-html", then "text/html".
+// A message with several bodies is delivered as multipart/alternative. It returns an
+// empty slice if no body is set.
+func (m *Message) BodyKinds() []string {
+	kinds := []string{}
+	if m.HasText() {
+		kinds = append(kinds, "text/plain")
+	}
+	if m.HasAMP() {
+		kinds = append(kinds, "text/x-amp-html")
+	}
+	if m.HasHTML() {
+		kinds = append(kinds, "text/html")
+	}
+	return kinds
+}
+
+// SetCharset labels the text and HTML bodies with a character set, e.g. "ISO-8859-1",
+// in the payload field "charset". Validate rejects charset names that are not
+// recognized. Returns the message for method chaining.
+//
+// The field is speculative: the Sendamatic API documentation does not list it, so the
+// API may ignore it and deliver the bodies as UTF-8. Nothing is transcoded either.
+// JSON strings are always Unicode, so an API that applied the label without
+// converting the bodies would deliver UTF-8 bytes declared as another charset. Do not
+// rely on it for receivers that require a legacy charset until Sendamatic confirms
+// how the field is handled.
+func (m *Message) SetCharset(charset string) *Message {
+	m.Charset = charset
+	return m
+}
+
+// SetCategory sets a category used to group messages for reporting in the Sendamatic dashboard.
+// Returns the message for method chaining.
+func (m *Message) SetCategory(category string) *Message {
+	m.Category = category
+	return m
+}
+
+// AddTag adds a tag used to filter and group messages for reporting in the Sendamatic
+// dashboard. Validate rejects empty tags. Returns the message for method chaining.
+func (m *Message) AddTag(tag string) *Message {
+	m.Tags = append(m.Tags, tag)
+	return m
+}
+
+// BodyEncoding is a Content-Transfer-Encoding requested for the text and HTML bodies,
+// or for a single attachment via Attachment.Encoding.
+type BodyEncoding string
+
+const (
+	// BodyEncodingAuto leaves the choice of transfer encoding to the API. This is the
+	// default for the bodies; attachments added with AttachFile and the other Attach
+	// methods default to BodyEncodingBase64.
+	BodyEncodingAuto BodyEncoding = ""
+	// BodyEncodingQuotedPrintable requests quoted-printable encoding, which keeps mostly
+	// ASCII text readable while wrapping long lines.
+	BodyEncodingQuotedPrintable BodyEncoding = "quoted-printable"
+	// BodyEncodingBase64 requests base64 encoding.
+	BodyEncodingBase64 BodyEncoding = "base64"
+)
+
+// maxLineOctets is the maximum length of a line in an SMTP message body, excluding CRLF (RFC 5321).
+const maxLineOctets = 998
+
+// SetBodyEncoding requests a transfer encoding for the text and HTML bodies.
+// By default (BodyEncodingAuto) the API chooses the encoding. Requesting
+// quoted-printable or base64 guarantees that bodies with lines longer than the SMTP
+// limit of 998 octets are wrapped safely; see CheckLineLength.
+// Returns the message for method chaining.
+func (m *Message) SetBodyEncoding(encoding BodyEncoding) *Message {
+	m.BodyEncoding = encoding
+	return m
+}
+
+// CheckLineLength reports whether the text or HTML body contains a line longer than
+// the SMTP limit of 998 octets. Such lines may be broken or rejected in transit unless
+// the body is transfer-encoded. The check is advisory and not part of Validate, since
+// the API usually encodes bodies as needed; it returns nil if no line is too long or if
+// an explicit encoding was requested with SetBodyEncoding.
+func (m *Message) CheckLineLength() error {
+	if m.BodyEncoding != BodyEncodingAuto {
+		return nil
+	}
+	for _, body := range []struct {
+		name string
+		text string
+	}{
+		{"text_body", m.TextBody},
+		{"html_body", m.HTMLBody},
+	} {
+		for i, line := range strings.Split(body.text, "\n") {
+			if n := len(strings.TrimSuffix(line, "\r")); n > maxLineOctets {
+				return invalidf(body.name, "%s line %d is %d octets long, exceeding the limit of %d", body.name, i+1, n, maxLineOctets)
+			}
+		}
+	}
+	return nil
+}
+
+// Queue names accepted by SetQueue.
+const (
+	// QueueTransactional routes the message through the queue for time-sensitive,
+	// individually triggered mail such as one-time passwords or password resets.
+	// It is delivered with the highest priority and protects its sending reputation
+	// by excluding bulk traffic.
+	QueueTransactional = "transactional"
+	// QueueBulk routes the message through the queue for high-volume mail such as
+	// newsletters. It may be delivered more slowly and is kept separate from
+	// transactional traffic so that bulk reputation does not affect it.
+	QueueBulk = "bulk"
+)
+
+// SetQueue selects the delivery queue for the message, either QueueTransactional or
+// QueueBulk. When no queue is set, the API's default routing applies. Validate rejects
+// unknown queue names. Returns the message for method chaining.
+func (m *Message) SetQueue(name string) *Message {
+	m.Queue = name
+	return m
+}
+
+// checkBodyContentType checks that contentType, if set, is a well-formed text/* media type.
+func checkBodyContentType(part, contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return invalidf(part+"_content_type", "invalid %s content type %q: %w", part, contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "text/") {
+		return invalidf(part+"_content_type", "invalid %s content type %q: must be a text/* media type", part, contentType)
+	}
+	return nil
+}
+
+// knownCharsets lists the character set names (lowercase) accepted by SetCharset.
+var knownCharsets = map[string]bool{
+	"utf-8": true, "us-ascii": true,
+
+	"iso-8859-1": true, "iso-8859-2": true, "iso-8859-3": true, "iso-8859-4": true,
+	"iso-8859-5": true, "iso-8859-6": true, "iso-8859-7": true, "iso-8859-8": true,
+	"iso-8859-9": true, "iso-8859-10": true, "iso-8859-13": true, "iso-8859-14": true,
+	"iso-8859-15": true, "iso-8859-16": true,
+
+	"windows-1250": true, "windows-1251": true, "windows-1252": true, "windows-1253": true,
+	"windows-1254": true, "windows-1255": true, "windows-1256": true, "windows-1257": true,
+	"windows-1258": true,
+
+	"koi8-r": true, "koi8-u": true,
+
+	"shift_jis": true, "euc-jp": true, "iso-2022-jp": true,
+	"euc-kr": true, "gb2312": true, "gbk": true, "gb18030": true, "big5": true,
+}
+
 // AddHeader adds a custom email header with the specified name and value.
 // Common examples include "Reply-To", "X-Priority", or custom application headers.
 // Returns the message for method chaining.
@@ -106,18 +628,189 @@ func (m *Message) AddHeader(name, value string) *Message {
 	return m
 }
 
+// GetHeader returns the value of the first custom header matching name (case-insensitive).
+// Returns the value and true if found, or empty string and false if not found.
+func (m *Message) GetHeader(name string) (string, bool) {
+	for _, h := range m.Headers {
+		if strings.EqualFold(h.Header, name) {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// RemoveHeader removes all custom headers matching name (case-insensitive),
+// preserving the order of the remaining headers.
+// Returns the message for method chaining.
+func (m *Message) RemoveHeader(name string) *Message {
+	headers := make([]Header, 0, len(m.Headers))
+	for _, h := range m.Headers {
+		if !strings.EqualFold(h.Header, name) {
+			headers = append(headers, h)
+		}
+	}
+	m.Headers = headers
+	return m
+}
+
+// AddHeaders adds multiple custom email headers from a map of names to values.
+// Headers are added in sorted order of their names so that payloads are reproducible.
+// Returns the message for method chaining.
+func (m *Message) AddHeaders(headers map[string]string) *Message {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m.AddHeader(name, headers[name])
+	}
+	return m
+}
+
+// SetHeaders replaces all custom email headers with the given headers.
+// Returns the message for method chaining.
+func (m *Message) SetHeaders(headers []Header) *Message {
+	m.Headers = append([]Header{}, headers...)
+	return m
+}
+
+// SetListUnsubscribe sets the List-Unsubscribe header from the given mailto: and https:
+// URLs, formatting each as an angle-bracketed entry separated by commas (RFC 2369).
+// If any https URL is present, "List-Unsubscribe-Post: List-Unsubscribe=One-Click"
+// is added as well to enable one-click unsubscription (RFC 8058).
+// Calling it again replaces any previously set values; calling it without URLs
+// removes both headers. Returns the message for method chaining.
+func (m *Message) SetListUnsubscribe(urls ...string) *Message {
+	m.RemoveHeader("List-Unsubscribe")
+	m.RemoveHeader("List-Unsubscribe-Post")
+	if len(urls) == 0 {
+		return m
+	}
+
+	entries := make([]string, len(urls))
+	oneClick := false
+	for i, u := range urls {
+		u = strings.Trim(strings.TrimSpace(u), "<>")
+		if strings.HasPrefix(strings.ToLower(u), "https://") {
+			oneClick = true
+		}
+		entries[i] = "<" + u + ">"
+	}
+
+	m.AddHeader("List-Unsubscribe", strings.Join(entries, ", "))
+	if oneClick {
+		m.AddHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	return m
+}
+
 // AttachFile adds a file attachment to the message from a byte slice.
-// The data is automatically base64-encoded for transmission.
+// The data is automatically base64-encoded for transmission, and the attachment is
+// declared with base64 transfer encoding.
 // Returns the message for method chaining.
 func (m *Message) AttachFile(filename, mimeType string, data []byte) *Message {
 	m.Attachments = append(m.Attachments, Attachment{
 		Filename: filename,
 		Data:     base64.StdEncoding.EncodeToString(data),
 		MimeType: mimeType,
+		Encoding: BodyEncodingBase64,
+	})
+	return m
+}
+
+// base64LineLength is the maximum length of a base64 line in MIME bodies (RFC 2045).
+const base64LineLength = 76
+
+// AttachFileWrapped adds a file attachment like AttachFile, but wraps the base64 data
+// into lines of 76 characters separated by CRLF, as RFC 2045 requires for MIME bodies.
+// Use it for interoperability with strict MIME parsers downstream that reject the
+// single long line AttachFile produces. AttachFile remains the default, since the API
+// accepts unwrapped data and wrapping makes the payload slightly larger.
+// Returns the message for method chaining.
+func (m *Message) AttachFileWrapped(filename, mimeType string, data []byte) *Message {
+	m.Attachments = append(m.Attachments, Attachment{
+		Filename: filename,
+		Data:     wrapBase64(base64.StdEncoding.EncodeToString(data)),
+		MimeType: mimeType,
+		Encoding: BodyEncodingBase64,
 	})
 	return m
 }
 
+// wrapBase64 breaks base64 data into lines of base64LineLength characters separated by
+// CRLF, without a trailing line break.
+func wrapBase64(data string) string {
+	if len(data) <= base64LineLength {
+		return data
+	}
+	var b strings.Builder
+	b.Grow(len(data) + len(data)/base64LineLength*2)
+	for len(data) > base64LineLength {
+		b.WriteString(data[:base64LineLength])
+		b.WriteString("\r\n")
+		data = data[base64LineLength:]
+	}
+	b.WriteString(data)
+	return b.String()
+}
+
+// calendarMethods lists the iTIP methods (RFC 5546) accepted for text/calendar attachments.
+var calendarMethods = map[string]bool{
+	"REQUEST": true,
+	"CANCEL":  true,
+	"PUBLISH": true,
+}
+
+// AttachICS attaches an iCalendar invite as "invite.ics" with the content type
+// "text/calendar; method=<method>", which mail clients such as Outlook and Gmail need
+// to render the invite UI. The method must be REQUEST, CANCEL or PUBLISH and should
+// match the METHOD property inside data; it is upper-cased, and Validate rejects any
+// other value. The API requires no additional headers for calendar attachments.
+// Returns the message for method chaining.
+//
+// Example:
+//
+//	msg.AttachICS(icsData, "REQUEST")
+func (m *Message) AttachICS(data []byte, method string) *Message {
+	mimeType := mime.FormatMediaType("text/calendar", map[string]string{"method": strings.ToUpper(method)})
+	return m.AttachFile("invite.ics", mimeType, data)
+}
+
+// sniffableMimeTypes lists declared MIME types that AttachFileChecked verifies against
+// the content. Only formats with reliable signatures are included to avoid false positives.
+var sniffableMimeTypes = map[string]bool{
+	"image/png":          true,
+	"image/jpeg":         true,
+	"image/gif":          true,
+	"image/webp":         true,
+	"image/bmp":          true,
+	"application/pdf":    true,
+	"application/zip":    true,
+	"application/x-gzip": true,
+}
+
+// AttachFileChecked works like AttachFile but first verifies that the declared MIME type
+// matches the content as detected by http.DetectContentType. The check only applies to
+// common formats with reliable signatures (PNG, JPEG, GIF, WebP, BMP, PDF, ZIP and gzip);
+// other declared types are accepted as-is. Returns an error and leaves the message
+// unchanged on a mismatch.
+func (m *Message) AttachFileChecked(filename, mimeType string, data []byte) error {
+	declared, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return fmt.Errorf("invalid mime type %q for %s: %w", mimeType, filename, err)
+	}
+	if sniffableMimeTypes[declared] {
+		detected, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+		if detected != declared {
+			return fmt.Errorf("attachment %s declared as %s but content looks like %s", filename, declared, detected)
+		}
+	}
+
+	m.AttachFile(filename, mimeType, data)
+	return nil
+}
+
 // AttachFileFromPath reads a file from the filesystem and adds it as an attachment.
 // The filename is extracted from the path. Returns an error if the file cannot be read.
 // The file data is automatically base64-encoded for transmission.
@@ -142,28 +835,405 @@ func (m *Message) AttachFileFromPath(path, mimeType string) error {
 	return nil
 }
 
+// maxAttachmentFetchBytes is the maximum size of content read by AttachFileFromURL
+// and AttachMultipart.
+const maxAttachmentFetchBytes = 25 << 20
+
+// AttachFileFromURL downloads the content at rawURL using http.DefaultClient and adds it
+// as an attachment. See AttachFileFromURLWithClient for details.
+func (m *Message) AttachFileFromURL(ctx context.Context, rawURL, mimeType string) error {
+	return m.AttachFileFromURLWithClient(ctx, http.DefaultClient, rawURL, mimeType)
+}
+
+// AttachFileFromURLWithClient downloads the content at rawURL using the given HTTP client
+// and adds it as an attachment. The request is bound to ctx. The filename is taken from
+// the last element of the URL path, and if mimeType is empty, the Content-Type of the
+// response is used. Returns an error if the request fails, the server responds with a
+// non-2xx status, or the content exceeds 25MB.
+func (m *Message) AttachFileFromURLWithClient(ctx context.Context, client *http.Client, rawURL, mimeType string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid attachment url: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch attachment: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to fetch attachment: unexpected status %d from %s", resp.StatusCode, u.Redacted())
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentFetchBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if len(data) > maxAttachmentFetchBytes {
+		return fmt.Errorf("attachment exceeds limit of %d bytes", maxAttachmentFetchBytes)
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "." || filename == "/" {
+		filename = "attachment"
+	}
+	if mimeType == "" {
+		mimeType = resp.Header.Get("Content-Type")
+	}
+
+	m.AttachFile(filename, mimeType, data)
+	return nil
+}
+
+// AttachMultipart adds a file uploaded through a multipart form, such as one obtained
+// from http.Request.FormFile, as an attachment. The filename and Content-Type are taken
+// from fh; uploads without a Content-Type are attached as application/octet-stream.
+// Returns an error if the upload is larger than 25MB or cannot be read.
+//
+// Example:
+//
+//	_, fh, err := r.FormFile("document")
+//	if err == nil {
+//		err = msg.AttachMultipart(fh)
+//	}
+func (m *Message) AttachMultipart(fh *multipart.FileHeader) error {
+	if fh.Size > maxAttachmentFetchBytes {
+		return fmt.Errorf("attachment %s exceeds limit of %d bytes", fh.Filename, maxAttachmentFetchBytes)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open upload %s: %w", fh.Filename, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxAttachmentFetchBytes+1))
+	if err != nil {
+		return fmt.Errorf("failed to read upload %s: %w", fh.Filename, err)
+	}
+	if len(data) > maxAttachmentFetchBytes {
+		return fmt.Errorf("attachment %s exceeds limit of %d bytes", fh.Filename, maxAttachmentFetchBytes)
+	}
+
+	mimeType := fh.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	m.AttachFile(fh.Filename, mimeType, data)
+	return nil
+}
+
+// ToJSON serializes the message for persistence, e.g. to store drafts. Recipients,
+// bodies, headers and attachments are preserved exactly and can be restored with
+// MessageFromJSON. Validation settings such as RejectDuplicateRecipients are not stored.
+func (m *Message) ToJSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// MessageFromJSON restores a message previously serialized with ToJSON.
+// Returns an error if data is not a valid serialized message.
+func MessageFromJSON(data []byte) (*Message, error) {
+	msg := NewMessage()
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return msg, nil
+}
+
+// MarshalReadable returns an indented JSON representation of the message intended for
+// logging and debugging. Attachment data is replaced with a short summary such as
+// "<base64 1234 bytes>" so that logs stay readable; all other fields are included as-is.
+// The result is not a valid API payload and must not be sent.
+func (m *Message) MarshalReadable() ([]byte, error) {
+	readable := *m
+	readable.Attachments = make([]Attachment, len(m.Attachments))
+	for i, a := range m.Attachments {
+		a.Data = fmt.Sprintf("<base64 %d bytes>", len(a.Data))
+		readable.Attachments[i] = a
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(readable); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// DebugString returns the output of MarshalReadable as a string.
+// If the message cannot be marshaled, the marshaling error is returned as text.
+func (m *Message) DebugString() string {
+	data, err := m.MarshalReadable()
+	if err != nil {
+		return fmt.Sprintf("<unmarshalable message: %v>", err)
+	}
+	return string(data)
+}
+
+// EstimatedSize returns the approximate size in bytes of the JSON payload that Send
+// transmits for this message, including the base64-encoded attachment data.
+// The estimate is computed without serializing the message, so it is cheap to call
+// for messages with large attachments.
+func (m *Message) EstimatedSize() int64 {
+	size := int64(len(`{"to":[],"sender":"","subject":""}`))
+	size += jsonStringLen(m.Sender) + jsonStringLen(m.Subject)
+	size += jsonStringListLen(m.To)
+	if len(m.CC) > 0 {
+		size += int64(len(`,"cc":[]`)) + jsonStringListLen(m.CC)
+	}
+	if len(m.BCC) > 0 {
+		size += int64(len(`,"bcc":[]`)) + jsonStringListLen(m.BCC)
+	}
+	if m.TextBody != "" {
+		size += int64(len(`,"text_body":""`)) + jsonStringLen(m.TextBody)
+	}
+	if m.HTMLBody != "" {
+		size += int64(len(`,"html_body":""`)) + jsonStringLen(m.HTMLBody)
+	}
+	if m.AMPBody != "" {
+		size += int64(len(`,"amp_body":""`)) + jsonStringLen(m.AMPBody)
+	}
+	if m.TextContentType != "" {
+		size += int64(len(`,"text_content_type":""`)) + jsonStringLen(m.TextContentType)
+	}
+	if m.HTMLContentType != "" {
+		size += int64(len(`,"html_content_type":""`)) + jsonStringLen(m.HTMLContentType)
+	}
+	if m.Charset != "" {
+		size += int64(len(`,"charset":""`)) + jsonStringLen(m.Charset)
+	}
+	if m.BodyEncoding != "" {
+		size += int64(len(`,"body_encoding":""`)) + jsonStringLen(string(m.BodyEncoding))
+	}
+	if len(m.Headers) > 0 {
+		size += int64(len(`,"headers":[]`)) + int64(len(m.Headers)-1)
+		for _, h := range m.Headers {
+			size += int64(len(`{"header":"","value":""}`)) + jsonStringLen(h.Header) + jsonStringLen(h.Value)
+		}
+	}
+	if n := len(m.Attachments) + len(m.sources); n > 0 {
+		size += int64(len(`,"attachments":[]`)) + int64(n-1)
+		for _, a := range m.Attachments {
+			size += int64(len(`{"filename":"","data":"","mimetype":""}`)) +
+				jsonStringLen(a.Filename) + jsonStringLen(a.Data) + jsonStringLen(a.MimeType)
+			if a.Encoding != "" {
+				size += int64(len(`,"encoding":""`)) + jsonStringLen(string(a.Encoding))
+			}
+		}
+		for _, src := range m.sources {
+			size += int64(len(`{"filename":"","data":"","mimetype":"","encoding":"base64"}`)) +
+				jsonStringLen(src.Filename()) + base64EncodedLen(src.Size()) + jsonStringLen(src.MimeType())
+		}
+	}
+	if m.Category != "" {
+		size += int64(len(`,"category":""`)) + jsonStringLen(m.Category)
+	}
+	if len(m.Tags) > 0 {
+		size += int64(len(`,"tags":[]`)) + jsonStringListLen(m.Tags)
+	}
+	if m.Queue != "" {
+		size += int64(len(`,"queue":""`)) + jsonStringLen(m.Queue)
+	}
+	if len(m.RecipientVariables) > 0 {
+		// Variables are small and of arbitrary types, so they are measured by encoding them
+		if vars, err := json.Marshal(m.RecipientVariables); err == nil {
+			size += int64(len(`,"recipient_variables":`)) + int64(len(vars))
+		}
+	}
+	return size
+}
+
+// jsonStringListLen returns the encoded length of the elements of a JSON string array,
+// excluding the surrounding brackets.
+func jsonStringListLen(list []string) int64 {
+	if len(list) == 0 {
+		return 0
+	}
+	size := int64(len(list) - 1)
+	for _, s := range list {
+		size += 2 + jsonStringLen(s)
+	}
+	return size
+}
+
+// jsonStringLen returns the length of s after JSON escaping as performed by
+// encoding/json, excluding the surrounding quotes.
+func jsonStringLen(s string) int64 {
+	size := int64(len(s))
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\' || c == '\n' || c == '\r' || c == '\t':
+			size++
+		case c < 0x20 || c == '<' || c == '>' || c == '&':
+			size += 5
+		}
+	}
+	return size
+}
+
 // Validate checks whether the message meets all required criteria for sending.
-// It returns an error if any validation rules are violated:
-//   - At least one recipient is required
-//   - Maximum of 255 recipients allowed
+// It returns a *ValidationError for the first rule that is violated:
+//   - At least one recipient is required in To, CC or BCC; To may be empty,
+//     e.g. for announcements sent to BCC recipients only
+//   - Maximum of 255 recipients allowed in To; a client configured with
+//     WithMaxRecipients applies its own limit when sending
 //   - Sender must be specified
 //   - Subject must be specified
 //   - Either TextBody or HTMLBody (or both) must be provided
+//   - An AMPBody requires an HTMLBody as fallback
+//   - TextContentType and HTMLContentType, if set, must be well-formed text/* media types
+//   - Charset, if set, must be a recognized character set name
+//   - BodyEncoding, if set, must be quoted-printable or base64
+//   - A Return-Path header, if set, must contain a valid email address
+//   - Message-ID and In-Reply-To headers, if set, must have the form "<local@domain>",
+//     as must every ID in a References header
+//   - Every attachment, including those added with AttachSource, must have a filename
+//     without path separators and non-empty data, and its Encoding, if set, must be quoted-printable or base64
+//   - A calendar attachment's method, if set, must be REQUEST, CANCEL or PUBLISH
+//   - Tags must not be empty
+//   - Queue, if set, must be QueueTransactional or QueueBulk
+//   - Recipient variables must only belong to recipients of the message
+//   - No duplicate recipients, if enabled via RejectDuplicateRecipients
+//   - The sender is not also a recipient, if enabled via RejectSenderAsRecipient
+//
+// Use ValidateAll to get every violation at once.
 func (m *Message) Validate() error {
-	if len(m.To) == 0 {
-		return errors.New("at least one recipient required")
+	return m.validate(maxRecipients)
+}
+
+// ValidateAll checks the message against the same rules as Validate, but returns every
+// violation instead of only the first, so that interactive users can fix all problems
+// at once. Each error is a *ValidationError, in the order Validate checks the rules;
+// the first one is the error Validate returns. ValidateAll returns nil if the message
+// is valid.
+func (m *Message) ValidateAll() []error {
+	return m.validationErrors(maxRecipients)
+}
+
+// validate implements Validate with the given maximum number of To recipients.
+func (m *Message) validate(maxTo int) error {
+	if errs := m.validationErrors(maxTo); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// validationErrors implements ValidateAll with the given maximum number of To recipients.
+func (m *Message) validationErrors(maxTo int) []error {
+	var errs []error
+	add := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
-	if len(m.To) > 255 {
-		return errors.New("maximum 255 recipients allowed")
+
+	if len(m.To)+len(m.CC)+len(m.BCC) == 0 {
+		add(invalidf("to", "at least one recipient required"))
+	}
+	if len(m.To) > maxTo {
+		add(invalidf("to", "maximum %d recipients allowed", maxTo))
 	}
 	if m.Sender == "" {
-		return errors.New("sender is required")
+		add(invalidf("sender", "sender is required"))
 	}
 	if m.Subject == "" {
-		return errors.New("subject is required")
+		add(invalidf("subject", "subject is required"))
 	}
-	if m.TextBody == "" && m.HTMLBody == "" {
-		return errors.New("either text_body or html_body is required")
+	if !m.HasText() && !m.HasHTML() {
+		add(invalidf("text_body", "either text_body or html_body is required"))
 	}
-	return nil
+	if m.HasAMP() && !m.HasHTML() {
+		add(invalidf("html_body", "html_body is required as fallback for amp_body"))
+	}
+	add(checkBodyContentType("text", m.TextContentType))
+	add(checkBodyContentType("html", m.HTMLContentType))
+	if m.Charset != "" && !knownCharsets[strings.ToLower(m.Charset)] {
+		add(invalidf("charset", "unsupported charset: %s", m.Charset))
+	}
+	switch m.BodyEncoding {
+	case BodyEncodingAuto, BodyEncodingQuotedPrintable, BodyEncodingBase64:
+	default:
+		add(invalidf("body_encoding", "unsupported body encoding: %s", m.BodyEncoding))
+	}
+	for i, h := range m.Headers {
+		field := fmt.Sprintf("headers[%d]", i)
+		switch {
+		case strings.EqualFold(h.Header, "Return-Path"):
+			if _, err := mail.ParseAddress(h.Value); err != nil {
+				add(invalidf(field, "invalid return path %q: %w", h.Value, err))
+			}
+		case strings.EqualFold(h.Header, "Message-ID"), strings.EqualFold(h.Header, "In-Reply-To"):
+			if !validMessageID(h.Value) {
+				add(invalidf(field, "invalid message id %q in %s", h.Value, h.Header))
+			}
+		case strings.EqualFold(h.Header, "References"):
+			for _, id := range strings.Fields(h.Value) {
+				if !validMessageID(id) {
+					add(invalidf(field, "invalid message id %q in %s", id, h.Header))
+				}
+			}
+		}
+	}
+	for i, a := range m.Attachments {
+		field := fmt.Sprintf("attachments[%d]", i)
+		if a.Filename == "" {
+			add(invalidf(field, "attachment %d: filename is required", i))
+		}
+		if strings.ContainsAny(a.Filename, `/\`) {
+			add(invalidf(field, "attachment %d: filename %q must not contain path separators", i, a.Filename))
+		}
+		if a.Data == "" {
+			add(invalidf(field, "attachment %d: data is empty", i))
+		}
+		switch a.Encoding {
+		case BodyEncodingAuto, BodyEncodingQuotedPrintable, BodyEncodingBase64:
+		default:
+			add(invalidf(field, "attachment %d: unsupported encoding: %s", i, a.Encoding))
+		}
+		if mediaType, params, err := mime.ParseMediaType(a.MimeType); err == nil && mediaType == "text/calendar" {
+			if method, ok := params["method"]; ok && !calendarMethods[method] {
+				add(invalidf(field, "attachment %d: unsupported calendar method: %q", i, method))
+			}
+		}
+	}
+	for j, src := range m.sources {
+		i := len(m.Attachments) + j
+		field := fmt.Sprintf("attachments[%d]", i)
+		if src.Filename() == "" {
+			add(invalidf(field, "attachment %d: filename is required", i))
+		}
+		if strings.ContainsAny(src.Filename(), `/\`) {
+			add(invalidf(field, "attachment %d: filename %q must not contain path separators", i, src.Filename()))
+		}
+		if src.Size() <= 0 {
+			add(invalidf(field, "attachment %d: data is empty", i))
+		}
+	}
+	for i, tag := range m.Tags {
+		if strings.TrimSpace(tag) == "" {
+			add(invalidf(fmt.Sprintf("tags[%d]", i), "tag %d is empty", i))
+		}
+	}
+	switch m.Queue {
+	case "", QueueTransactional, QueueBulk:
+	default:
+		add(invalidf("queue", "unknown queue: %s", m.Queue))
+	}
+	errs = append(errs, m.checkRecipientVariables()...)
+	if m.rejectDuplicates {
+		errs = append(errs, m.checkDuplicateRecipients()...)
+	}
+	if m.rejectSelfSend {
+		errs = append(errs, m.checkSenderAsRecipient()...)
+	}
+	return errs
 }
@@ -1,9 +1,21 @@
 package sendamatic
 
 import (
+	"bytes"
+	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -23,6 +35,24 @@ func TestNewMessage(t *testing.T) {
 	}
 }
 
+func TestNewSimpleMessage(t *testing.T) {
+	msg := NewSimpleMessage("sender@example.com", "to@example.com", "Subject", "Body").
+		AddCC("cc@example.com")
+
+	if msg.Sender != "sender@example.com" || msg.Subject != "Subject" || msg.TextBody != "Body" {
+		t.Errorf("NewSimpleMessage() = %+v, want sender, subject and text body set", msg)
+	}
+	if len(msg.To) != 1 || msg.To[0] != "to@example.com" {
+		t.Errorf("To = %v, want [to@example.com]", msg.To)
+	}
+	if len(msg.CC) != 1 || msg.BCC == nil || msg.Headers == nil || msg.Attachments == nil {
+		t.Error("Slices not initialized as by NewMessage")
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
 func TestMessageBuilderMethods(t *testing.T) {
 	msg := NewMessage().
 		SetSender("sender@example.com").
@@ -198,6 +228,22 @@ func TestValidate_Success(t *testing.T) {
 				SetTextBody("Body").
 				SetHTMLBody("<p>Body</p>"),
 		},
+		{
+			name: "valid with bcc only",
+			msg: NewMessage().
+				SetSender("sender@example.com").
+				AddBCC("bcc@example.com").
+				SetSubject("Subject").
+				SetTextBody("Body"),
+		},
+		{
+			name: "valid with cc only",
+			msg: NewMessage().
+				SetSender("sender@example.com").
+				AddCC("cc@example.com").
+				SetSubject("Subject").
+				SetTextBody("Body"),
+		},
 		{
 			name: "valid with multiple recipients",
 			msg: NewMessage().
@@ -221,6 +267,54 @@ func TestValidate_Success(t *testing.T) {
 	}
 }
 
+// validMessage returns a message that passes validation, for tests that modify it.
+func validMessage() *Message {
+	return NewMessage().
+		SetSender("sender@example.com").
+		AddTo("to@example.com").
+		SetSubject("Subject").
+		SetTextBody("Body")
+}
+
+func TestValidateAll(t *testing.T) {
+	if errs := validMessage().ValidateAll(); errs != nil {
+		t.Errorf("ValidateAll() = %v, want nil", errs)
+	}
+
+	msg := NewMessage().
+		AddTo("to@example.com").
+		AddCC("to@Example.com").
+		SetTextBody("Body").
+		AttachFile("", "text/plain", nil).
+		AddTag("").
+		RejectDuplicateRecipients(false)
+
+	want := []string{
+		"sender is required",
+		"subject is required",
+		"attachment 0: filename is required",
+		"attachment 0: data is empty",
+		"tag 0 is empty",
+		"duplicate recipient: to@Example.com",
+	}
+	errs := msg.ValidateAll()
+	if len(errs) != len(want) {
+		t.Fatalf("ValidateAll() = %v, want %d errors", errs, len(want))
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("ValidateAll()[%d] = %q, want %q", i, err.Error(), want[i])
+		}
+		var valErr *ValidationError
+		if !errors.As(err, &valErr) {
+			t.Errorf("ValidateAll()[%d] = %T, want *ValidationError", i, err)
+		}
+	}
+	if err := msg.Validate(); err == nil || err.Error() != errs[0].Error() {
+		t.Errorf("Validate() = %v, want first error of ValidateAll %v", err, errs[0])
+	}
+}
+
 func TestValidate_Errors(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -247,6 +341,35 @@ func TestValidate_Errors(t *testing.T) {
 			msg:         NewMessage().SetSender("sender@example.com").AddTo("to@example.com").SetSubject("Subject"),
 			wantErrText: "either text_body or html_body is required",
 		},
+		{
+			name:        "attachment without filename",
+			msg:         validMessage().AttachFile("", "text/plain", []byte("data")),
+			wantErrText: "attachment 0: filename is required",
+		},
+		{
+			name:        "attachment with path in filename",
+			msg:         validMessage().AttachFile("ok.txt", "text/plain", []byte("data")).AttachFile("dir/file.txt", "text/plain", []byte("data")),
+			wantErrText: `attachment 1: filename "dir/file.txt" must not contain path separators`,
+		},
+		{
+			name:        "attachment with windows path in filename",
+			msg:         validMessage().AttachFile(`C:\file.txt`, "text/plain", []byte("data")),
+			wantErrText: `attachment 0: filename "C:\\file.txt" must not contain path separators`,
+		},
+		{
+			name:        "attachment with empty data",
+			msg:         validMessage().AttachFile("empty.txt", "text/plain", nil),
+			wantErrText: "attachment 0: data is empty",
+		},
+		{
+			name: "attachment with unsupported encoding",
+			msg: func() *Message {
+				msg := validMessage().AttachFile("a.txt", "text/plain", []byte("data"))
+				msg.Attachments[0].Encoding = "7bit"
+				return msg
+			}(),
+			wantErrText: "attachment 0: unsupported encoding: 7bit",
+		},
 	}
 
 	for _, tt := range tests {
@@ -283,3 +406,1003 @@ func TestValidate_TooManyRecipients(t *testing.T) {
 		t.Errorf("Validate() error = %q, want %q", err.Error(), expected)
 	}
 }
+
+func TestMarshalReadable(t *testing.T) {
+	data := []byte(strings.Repeat("a", 300))
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("to@example.com").
+		SetSubject("Subject").
+		SetTextBody("Body").
+		AddHeader("X-Test", "value").
+		AttachFile("file.bin", "application/octet-stream", data)
+
+	out, err := msg.MarshalReadable()
+	if err != nil {
+		t.Fatalf("MarshalReadable() error = %v", err)
+	}
+
+	readable := string(out)
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if strings.Contains(readable, encoded) {
+		t.Error("Readable output contains raw attachment data")
+	}
+	want := fmt.Sprintf("<base64 %d bytes>", len(encoded))
+	if !strings.Contains(readable, want) {
+		t.Errorf("Readable output missing summary %q", want)
+	}
+	for _, s := range []string{"sender@example.com", "to@example.com", "Subject", "X-Test", "file.bin"} {
+		if !strings.Contains(readable, s) {
+			t.Errorf("Readable output missing %q", s)
+		}
+	}
+
+	// The original message must remain untouched
+	if msg.Attachments[0].Data != encoded {
+		t.Error("MarshalReadable modified the original attachment data")
+	}
+
+	if msg.DebugString() != readable {
+		t.Error("DebugString() does not match MarshalReadable output")
+	}
+}
+
+func TestSetListUnsubscribe(t *testing.T) {
+	msg := NewMessage().
+		AddHeader("X-Other", "keep").
+		SetListUnsubscribe("mailto:unsub@example.com", "https://example.com/unsub?id=1")
+
+	headers := map[string]string{}
+	for _, h := range msg.Headers {
+		headers[h.Header] = h.Value
+	}
+
+	wantList := "<mailto:unsub@example.com>, <https://example.com/unsub?id=1>"
+	if headers["List-Unsubscribe"] != wantList {
+		t.Errorf("List-Unsubscribe = %q, want %q", headers["List-Unsubscribe"], wantList)
+	}
+	if headers["List-Unsubscribe-Post"] != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q, want %q", headers["List-Unsubscribe-Post"], "List-Unsubscribe=One-Click")
+	}
+	if headers["X-Other"] != "keep" {
+		t.Error("Unrelated header was removed")
+	}
+
+	// Calling again replaces prior values
+	msg.SetListUnsubscribe("mailto:other@example.com")
+	if len(msg.Headers) != 2 {
+		t.Fatalf("Headers length = %d, want 2", len(msg.Headers))
+	}
+	if msg.Headers[1].Header != "List-Unsubscribe" || msg.Headers[1].Value != "<mailto:other@example.com>" {
+		t.Errorf("Header = %+v, want List-Unsubscribe <mailto:other@example.com>", msg.Headers[1])
+	}
+}
+
+func TestAttachFile_UTF8FilenameRoundTrip(t *testing.T) {
+	filenames := []string{"Rechnung_Übersicht.pdf", "請求書.pdf"}
+
+	for _, name := range filenames {
+		msg := NewMessage().AttachFile(name, "application/pdf", []byte("data"))
+
+		payload, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+
+		var decoded Message
+		if err := json.Unmarshal(payload, &decoded); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+
+		if decoded.Attachments[0].Filename != name {
+			t.Errorf("Filename = %q, want %q", decoded.Attachments[0].Filename, name)
+		}
+	}
+}
+
+func TestEstimatedSize(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  *Message
+	}{
+		{
+			name: "minimal",
+			msg: NewMessage().
+				SetSender("sender@example.com").
+				AddTo("to@example.com").
+				SetSubject("Subject").
+				SetTextBody("Body"),
+		},
+		{
+			name: "full",
+			msg: NewMessage().
+				SetSender("Sender <sender@example.com>").
+				AddTo("to1@example.com").
+				AddTo("to2@example.com").
+				AddCC("cc@example.com").
+				AddBCC("bcc@example.com").
+				SetSubject("Grüße \"quoted\"").
+				SetTextBody("Line 1\nLine 2\tTabbed").
+				SetHTMLBody("<h1>Hello & welcome</h1>").
+				AddHeader("Reply-To", "reply@example.com").
+				AddHeader("X-Priority", "1").
+				AttachFile("a.bin", "application/octet-stream", make([]byte, 4096)).
+				AttachFile("b.txt", "text/plain", []byte("text")).
+				SetCategory("billing").
+				AddTag("invoice").
+				AddTag("monthly").
+				SetQueue(QueueBulk).
+				AddToWithVars("Jane <jane@Example.com>", map[string]interface{}{"name": "Jane", "n": 3}),
+		},
+		{
+			name: "attachment encoding",
+			msg: func() *Message {
+				msg := validMessage().AttachFile("a.txt", "text/plain", []byte("text"))
+				msg.Attachments[0].Encoding = BodyEncodingQuotedPrintable
+				return msg
+			}(),
+		},
+		{
+			name: "body content types",
+			msg: validMessage().
+				SetTextBodyWithType("Body", "text/plain; format=flowed").
+				SetHTMLBodyWithType("<p>Body</p>", "text/html"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := json.Marshal(tt.msg)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+
+			if got := tt.msg.EstimatedSize(); got != int64(len(payload)) {
+				t.Errorf("EstimatedSize() = %d, want %d", got, len(payload))
+			}
+		})
+	}
+}
+
+func TestSetBodyWithType(t *testing.T) {
+	msg := validMessage().
+		SetTextBodyWithType("Body", "text/plain; format=flowed").
+		SetHTMLBodyWithType("<p>Body</p>", "text/html; charset=utf-8")
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	payload, _ := json.Marshal(msg)
+	if !strings.Contains(string(payload), `"text_content_type":"text/plain; format=flowed"`) {
+		t.Errorf("Payload = %s, want text_content_type field", payload)
+	}
+
+	payload, _ = json.Marshal(validMessage())
+	if strings.Contains(string(payload), "content_type") {
+		t.Errorf("Default payload = %s, want no content type fields", payload)
+	}
+
+	tests := []struct {
+		name    string
+		msg     *Message
+		wantErr string
+	}{
+		{
+			name:    "not text",
+			msg:     validMessage().SetHTMLBodyWithType("<p>Body</p>", "application/json"),
+			wantErr: `invalid html content type "application/json": must be a text/* media type`,
+		},
+		{
+			name:    "malformed",
+			msg:     validMessage().SetTextBodyWithType("Body", "text/plain; charset"),
+			wantErr: `invalid text content type "text/plain; charset": mime: invalid media parameter`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.msg.Validate(); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetCharset(t *testing.T) {
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("to@example.com").
+		SetSubject("Subject").
+		SetTextBody("Body")
+
+	payload, _ := json.Marshal(msg)
+	if strings.Contains(string(payload), "charset") {
+		t.Error("Default payload should not contain a charset")
+	}
+
+	msg.SetCharset("ISO-8859-1")
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	payload, _ = json.Marshal(msg)
+	if !strings.Contains(string(payload), `"charset":"ISO-8859-1"`) {
+		t.Errorf("Payload = %s, want charset field", payload)
+	}
+
+	msg.SetCharset("klingon-1")
+	err := msg.Validate()
+	if err == nil {
+		t.Fatal("Validate() error = nil, want error for unknown charset")
+	}
+	if err.Error() != "unsupported charset: klingon-1" {
+		t.Errorf("Validate() error = %q, want %q", err.Error(), "unsupported charset: klingon-1")
+	}
+}
+
+func TestAddHeaders(t *testing.T) {
+	msg := NewMessage().
+		AddHeader("X-First", "1").
+		AddHeaders(map[string]string{
+			"X-Zeta":   "z",
+			"Reply-To": "reply@example.com",
+			"X-Alpha":  "a",
+		})
+
+	want := []Header{
+		{Header: "X-First", Value: "1"},
+		{Header: "Reply-To", Value: "reply@example.com"},
+		{Header: "X-Alpha", Value: "a"},
+		{Header: "X-Zeta", Value: "z"},
+	}
+	if len(msg.Headers) != len(want) {
+		t.Fatalf("Headers length = %d, want %d", len(msg.Headers), len(want))
+	}
+	for i := range want {
+		if msg.Headers[i] != want[i] {
+			t.Errorf("Headers[%d] = %+v, want %+v", i, msg.Headers[i], want[i])
+		}
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	headers := []Header{{Header: "X-New", Value: "new"}}
+	msg := NewMessage().
+		AddHeader("X-Old", "old").
+		SetHeaders(headers)
+
+	if len(msg.Headers) != 1 || msg.Headers[0] != headers[0] {
+		t.Errorf("Headers = %+v, want %+v", msg.Headers, headers)
+	}
+
+	// The message must not share the caller's slice
+	msg.AddHeader("X-More", "more")
+	headers[0].Value = "changed"
+	if msg.Headers[0].Value != "new" {
+		t.Error("SetHeaders shares the caller's slice")
+	}
+}
+
+func TestMessage_MarshalDeterministic(t *testing.T) {
+	build := func() *Message {
+		return NewMessage().
+			SetSender("sender@example.com").
+			AddTo("to@example.com").
+			AddCC("cc@example.com").
+			AddBCC("bcc@example.com").
+			SetSubject("Subject").
+			SetTextBody("Text").
+			SetHTMLBody("HTML").
+			AddHeader("X-B", "2").
+			AddHeader("X-A", "1").
+			AddHeaders(map[string]string{"X-D": "4", "X-C": "3"}).
+			AttachFile("b.txt", "text/plain", []byte("b")).
+			AttachFile("a.txt", "text/plain", []byte("a"))
+	}
+
+	want := `{"to":["to@example.com"],"cc":["cc@example.com"],"bcc":["bcc@example.com"],` +
+		`"sender":"sender@example.com","subject":"Subject","text_body":"Text","html_body":"HTML",` +
+		`"headers":[{"header":"X-B","value":"2"},{"header":"X-A","value":"1"},` +
+		`{"header":"X-C","value":"3"},{"header":"X-D","value":"4"}],` +
+		`"attachments":[{"filename":"b.txt","data":"Yg==","mimetype":"text/plain","encoding":"base64"},` +
+		`{"filename":"a.txt","data":"YQ==","mimetype":"text/plain","encoding":"base64"}]}`
+
+	for i := 0; i < 10; i++ {
+		payload, err := json.Marshal(build())
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(payload) != want {
+			t.Fatalf("Payload = %s\nwant      %s", payload, want)
+		}
+	}
+}
+
+func TestSetReturnPath(t *testing.T) {
+	msg := validMessage().
+		SetReturnPath("bounces@example.com").
+		SetReturnPath("<bounces2@example.com>")
+
+	if len(msg.Headers) != 1 {
+		t.Fatalf("Headers length = %d, want 1", len(msg.Headers))
+	}
+	if msg.Headers[0].Header != "Return-Path" || msg.Headers[0].Value != "<bounces2@example.com>" {
+		t.Errorf("Header = %+v, want Return-Path <bounces2@example.com>", msg.Headers[0])
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	msg.SetReturnPath("not an address")
+	if err := msg.Validate(); err == nil {
+		t.Error("Validate() error = nil, want error for invalid return path")
+	}
+}
+
+func TestSetEnvelopeFrom(t *testing.T) {
+	msg := validMessage().SetEnvelopeFrom("bounces+recipient=example.com@mail.example.com")
+
+	if got := msg.EnvelopeFrom(); got != "bounces+recipient=example.com@mail.example.com" {
+		t.Errorf("EnvelopeFrom() = %q, want %q", got, "bounces+recipient=example.com@mail.example.com")
+	}
+	if msg.Sender != "sender@example.com" {
+		t.Errorf("Sender = %q, want it unchanged", msg.Sender)
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	msg.SetEnvelopeFrom("not an address")
+	var verr *ValidationError
+	if err := msg.Validate(); !errors.As(err, &verr) || verr.Field != "headers[0]" {
+		t.Errorf("Validate() error = %v, want ValidationError for headers[0]", err)
+	}
+
+	if got := validMessage().EnvelopeFrom(); got != "" {
+		t.Errorf("EnvelopeFrom() = %q, want empty", got)
+	}
+}
+
+func TestGetHeader(t *testing.T) {
+	msg := NewMessage().
+		AddHeader("Reply-To", "first@example.com").
+		AddHeader("reply-to", "second@example.com")
+
+	value, ok := msg.GetHeader("REPLY-TO")
+	if !ok || value != "first@example.com" {
+		t.Errorf("GetHeader() = %q, %v, want %q, true", value, ok, "first@example.com")
+	}
+
+	if _, ok := msg.GetHeader("X-Missing"); ok {
+		t.Error("GetHeader() ok = true for missing header")
+	}
+}
+
+func TestRemoveHeader(t *testing.T) {
+	msg := NewMessage().
+		AddHeader("X-A", "1").
+		AddHeader("X-Remove", "2").
+		AddHeader("X-B", "3").
+		AddHeader("x-remove", "4").
+		AddHeader("X-C", "5").
+		RemoveHeader("X-REMOVE")
+
+	want := []string{"X-A", "X-B", "X-C"}
+	if len(msg.Headers) != len(want) {
+		t.Fatalf("Headers length = %d, want %d", len(msg.Headers), len(want))
+	}
+	for i, name := range want {
+		if msg.Headers[i].Header != name {
+			t.Errorf("Headers[%d] = %q, want %q", i, msg.Headers[i].Header, name)
+		}
+	}
+}
+
+func TestMessage_Clone(t *testing.T) {
+	orig := validMessage().AddHeader("X-A", "1").AttachFile("a.txt", "text/plain", []byte("a")).
+		AddToWithVars("vars@example.com", map[string]interface{}{"name": "Jane"})
+	clone := orig.Clone()
+
+	clone.AddTo("other@example.com")
+	clone.Headers[0].Value = "changed"
+	clone.Attachments[0].Filename = "changed.txt"
+	clone.SetSubject("Changed")
+	clone.RecipientVariables["vars@example.com"]["name"] = "changed"
+
+	if len(orig.To) != 2 || orig.Headers[0].Value != "1" || orig.Attachments[0].Filename != "a.txt" || orig.Subject != "Subject" {
+		t.Error("Modifying the clone affected the original message")
+	}
+	if got := orig.RecipientVariables["vars@example.com"]["name"]; got != "Jane" {
+		t.Errorf("original variable = %v, want Jane", got)
+	}
+}
+
+func TestAttachFileFromURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/report.pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("pdf content"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	msg := NewMessage()
+	if err := msg.AttachFileFromURL(context.Background(), server.URL+"/files/report.pdf?sig=abc", ""); err != nil {
+		t.Fatalf("AttachFileFromURL() error = %v", err)
+	}
+
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("Attachments length = %d, want 1", len(msg.Attachments))
+	}
+	att := msg.Attachments[0]
+	if att.Filename != "report.pdf" {
+		t.Errorf("Filename = %q, want %q", att.Filename, "report.pdf")
+	}
+	if att.MimeType != "application/pdf" {
+		t.Errorf("MimeType = %q, want %q", att.MimeType, "application/pdf")
+	}
+	if att.Data != base64.StdEncoding.EncodeToString([]byte("pdf content")) {
+		t.Errorf("Data = %q, want encoded content", att.Data)
+	}
+
+	err := msg.AttachFileFromURL(context.Background(), server.URL+"/missing.pdf", "application/pdf")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("AttachFileFromURL() error = %v, want error mentioning 404", err)
+	}
+	if len(msg.Attachments) != 1 {
+		t.Error("Failed fetch must not add an attachment")
+	}
+}
+
+func TestAttachFileFromURL_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := NewMessage().AttachFileFromURLWithClient(ctx, server.Client(), server.URL+"/file.txt", "text/plain")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("AttachFileFromURLWithClient() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestCategoryAndTags(t *testing.T) {
+	msg := validMessage().
+		SetCategory("billing").
+		AddTag("invoice").
+		AddTag("monthly")
+
+	if err := msg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+
+	payload, _ := json.Marshal(msg)
+	if !strings.Contains(string(payload), `"category":"billing","tags":["invoice","monthly"]`) {
+		t.Errorf("Payload = %s, want category and tags", payload)
+	}
+
+	payload, _ = json.Marshal(validMessage())
+	if strings.Contains(string(payload), "category") || strings.Contains(string(payload), "tags") {
+		t.Errorf("Payload = %s, want no category or tags by default", payload)
+	}
+
+	err := validMessage().AddTag("ok").AddTag(" ").Validate()
+	if err == nil || err.Error() != "tag 1 is empty" {
+		t.Errorf("Validate() error = %v, want %q", err, "tag 1 is empty")
+	}
+}
+
+func TestAttachFileChecked(t *testing.T) {
+	pngData, err := os.ReadFile("testdata/test.png")
+	if err != nil {
+		t.Fatalf("Failed to read test.png: %v", err)
+	}
+	pdfData, err := os.ReadFile("testdata/test.pdf")
+	if err != nil {
+		t.Fatalf("Failed to read test.pdf: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		mimeType string
+		data     []byte
+		wantErr  bool
+	}{
+		{"matching png", "image/png", pngData, false},
+		{"matching pdf", "application/pdf", pdfData, false},
+		{"pdf labeled as png", "image/png", pdfData, true},
+		{"png labeled as pdf", "application/pdf", pngData, true},
+		{"uncommon type is not checked", "application/vnd.ms-excel", pngData, false},
+		{"text with parameters", "text/plain; charset=utf-8", []byte("hello"), false},
+		{"invalid mime type", "not a mime type", pngData, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage()
+			err := msg.AttachFileChecked("file", tt.mimeType, tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AttachFileChecked() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			wantAttachments := 1
+			if tt.wantErr {
+				wantAttachments = 0
+			}
+			if len(msg.Attachments) != wantAttachments {
+				t.Errorf("Attachments length = %d, want %d", len(msg.Attachments), wantAttachments)
+			}
+		})
+	}
+}
+
+func TestSetBodyEncoding(t *testing.T) {
+	msg := validMessage().SetBodyEncoding(BodyEncodingQuotedPrintable)
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	payload, _ := json.Marshal(msg)
+	if !strings.Contains(string(payload), `"body_encoding":"quoted-printable"`) {
+		t.Errorf("Payload = %s, want body_encoding", payload)
+	}
+
+	payload, _ = json.Marshal(validMessage())
+	if strings.Contains(string(payload), "body_encoding") {
+		t.Errorf("Payload = %s, want no body_encoding by default", payload)
+	}
+
+	err := validMessage().SetBodyEncoding("7bit").Validate()
+	if err == nil || err.Error() != "unsupported body encoding: 7bit" {
+		t.Errorf("Validate() error = %v, want unsupported body encoding", err)
+	}
+}
+
+func TestCheckLineLength(t *testing.T) {
+	longLine := strings.Repeat("x", 999)
+
+	if err := validMessage().SetHTMLBody("<p>short</p>\r\n" + strings.Repeat("x", 998)).CheckLineLength(); err != nil {
+		t.Errorf("CheckLineLength() error = %v, want nil", err)
+	}
+
+	err := validMessage().SetHTMLBody("<p>short</p>\n" + longLine).CheckLineLength()
+	if err == nil {
+		t.Fatal("CheckLineLength() error = nil, want error for long line")
+	}
+	if !strings.Contains(err.Error(), "html_body line 2") {
+		t.Errorf("CheckLineLength() error = %q, want to identify html_body line 2", err.Error())
+	}
+
+	msg := validMessage().SetTextBody(longLine)
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, long lines must not fail validation", err)
+	}
+	if err := msg.SetBodyEncoding(BodyEncodingBase64).CheckLineLength(); err != nil {
+		t.Errorf("CheckLineLength() error = %v, want nil with explicit encoding", err)
+	}
+}
+
+func TestMessage_JSONRoundTrip(t *testing.T) {
+	orig := validMessage().
+		AddCC("cc@example.com").
+		AddBCC("bcc@example.com").
+		SetHTMLBody("<p>Grüße & <b>Hallo</b></p>").
+		AddHeader("Reply-To", "reply@example.com").
+		AddHeader("X-Custom", "value").
+		AttachFile("Rechnung_Übersicht.pdf", "application/pdf", []byte{0x25, 0x50, 0x44, 0x46, 0x00, 0xff}).
+		SetCategory("billing").
+		AddTag("invoice")
+
+	data, err := orig.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+
+	restored, err := MessageFromJSON(data)
+	if err != nil {
+		t.Fatalf("MessageFromJSON() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(restored, orig) {
+		t.Errorf("Restored message = %+v, want %+v", restored, orig)
+	}
+
+	if _, err := MessageFromJSON([]byte("not json")); err == nil {
+		t.Error("MessageFromJSON() error = nil, want error for invalid JSON")
+	}
+}
+
+func TestSetQueue(t *testing.T) {
+	for _, queue := range []string{QueueTransactional, QueueBulk} {
+		msg := validMessage().SetQueue(queue)
+		if err := msg.Validate(); err != nil {
+			t.Errorf("Validate() with queue %q error = %v, want nil", queue, err)
+		}
+		payload, _ := json.Marshal(msg)
+		if !strings.Contains(string(payload), `"queue":"`+queue+`"`) {
+			t.Errorf("Payload = %s, want queue %q", payload, queue)
+		}
+	}
+
+	err := validMessage().SetQueue("express").Validate()
+	if err == nil || err.Error() != "unknown queue: express" {
+		t.Errorf("Validate() error = %v, want %q", err, "unknown queue: express")
+	}
+}
+
+func TestAttachment_Encoding(t *testing.T) {
+	msg := validMessage().AttachFile("a.txt", "text/plain", []byte("text"))
+
+	payload, _ := json.Marshal(msg)
+	if !strings.Contains(string(payload), `"encoding":"base64"`) {
+		t.Errorf("Payload = %s, want base64 encoding for default attachment", payload)
+	}
+
+	msg.Attachments[0].Encoding = BodyEncodingAuto
+	payload, _ = json.Marshal(msg)
+	if strings.Contains(string(payload), `"encoding"`) {
+		t.Errorf("Payload = %s, want no encoding for BodyEncodingAuto", payload)
+	}
+
+	msg.Attachments[0].Encoding = BodyEncodingQuotedPrintable
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	payload, _ = json.Marshal(msg)
+	if !strings.Contains(string(payload), `"encoding":"quoted-printable"`) {
+		t.Errorf("Payload = %s, want quoted-printable encoding", payload)
+	}
+}
+
+func TestBodyKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      *Message
+		wantText bool
+		wantHTML bool
+		want     []string
+	}{
+		{"none", NewMessage(), false, false, []string{}},
+		{"text", NewMessage().SetTextBody("Hi"), true, false, []string{"text/plain"}},
+		{"html", NewMessage().SetHTMLBody("<p>Hi</p>"), false, true, []string{"text/html"}},
+		{"both", NewMessage().SetHTMLBody("<p>Hi</p>").SetTextBody("Hi"), true, true, []string{"text/plain", "text/html"}},
+		{"amp", NewMessage().SetHTMLBody("<p>Hi</p>").SetAMPBody("<html amp4email></html>").SetTextBody("Hi"), true, true,
+			[]string{"text/plain", "text/x-amp-html", "text/html"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.msg.HasText(); got != tt.wantText {
+				t.Errorf("HasText() = %v, want %v", got, tt.wantText)
+			}
+			if got := tt.msg.HasHTML(); got != tt.wantHTML {
+				t.Errorf("HasHTML() = %v, want %v", got, tt.wantHTML)
+			}
+			if got := tt.msg.BodyKinds(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BodyKinds() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAMPBody(t *testing.T) {
+	amp := `<!doctype html><html ⚡4email><head></head><body>Hi</body></html>`
+	msg := validMessage().SetHTMLBody("<p>Hi</p>").SetAMPBody(amp)
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	payload, _ := json.Marshal(msg)
+	if !strings.Contains(string(payload), `"amp_body":`) {
+		t.Errorf("Payload = %s, want amp_body field", payload)
+	}
+	if got := msg.EstimatedSize(); got != int64(len(payload)) {
+		t.Errorf("EstimatedSize() = %d, want %d", got, len(payload))
+	}
+
+	msg = validMessage().SetAMPBody(amp)
+	want := "html_body is required as fallback for amp_body"
+	if err := msg.Validate(); err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %q", err, want)
+	}
+}
+
+func TestAttachICS(t *testing.T) {
+	data := []byte("BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nEND:VCALENDAR\r\n")
+	msg := validMessage().AttachICS(data, "request")
+
+	if len(msg.Attachments) != 1 {
+		t.Fatalf("Attachments length = %d, want 1", len(msg.Attachments))
+	}
+	att := msg.Attachments[0]
+	if att.Filename != "invite.ics" {
+		t.Errorf("Filename = %q, want %q", att.Filename, "invite.ics")
+	}
+	if att.MimeType != "text/calendar; method=REQUEST" {
+		t.Errorf("MimeType = %q, want %q", att.MimeType, "text/calendar; method=REQUEST")
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	for _, method := range []string{"REPLY", ""} {
+		err := validMessage().AttachICS(data, method).Validate()
+		want := fmt.Sprintf("attachment 0: unsupported calendar method: %q", method)
+		if err == nil || err.Error() != want {
+			t.Errorf("Validate() with method %q error = %v, want %q", method, err, want)
+		}
+	}
+
+	if err := validMessage().AttachFile("plain.ics", "text/calendar", data).Validate(); err != nil {
+		t.Errorf("Validate() without method error = %v, want nil", err)
+	}
+}
+
+// newMultipartUpload parses a multipart form containing a single file field and
+// returns its file header, as an HTTP handler would receive it.
+func newMultipartUpload(t *testing.T, filename, contentType string, data []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	part, err := mw.CreatePart(h)
+	if err != nil {
+		t.Fatalf("CreatePart failed: %v", err)
+	}
+	part.Write(data)
+	mw.Close()
+
+	form, err := multipart.NewReader(&body, mw.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("ReadForm failed: %v", err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
+
+func TestAttachMultipart(t *testing.T) {
+	data := []byte("%PDF-1.4 upload")
+	msg := NewMessage()
+
+	if err := msg.AttachMultipart(newMultipartUpload(t, "report.pdf", "application/pdf", data)); err != nil {
+		t.Fatalf("AttachMultipart() error = %v", err)
+	}
+	if err := msg.AttachMultipart(newMultipartUpload(t, "notes.bin", "", []byte("raw"))); err != nil {
+		t.Fatalf("AttachMultipart() error = %v", err)
+	}
+
+	want := []Attachment{
+		{Filename: "report.pdf", Data: base64.StdEncoding.EncodeToString(data), MimeType: "application/pdf", Encoding: BodyEncodingBase64},
+		{Filename: "notes.bin", Data: base64.StdEncoding.EncodeToString([]byte("raw")), MimeType: "application/octet-stream", Encoding: BodyEncodingBase64},
+	}
+	if !reflect.DeepEqual(msg.Attachments, want) {
+		t.Errorf("Attachments = %+v, want %+v", msg.Attachments, want)
+	}
+}
+
+func TestAttachMultipart_TooLarge(t *testing.T) {
+	fh := newMultipartUpload(t, "big.bin", "application/octet-stream", []byte("data"))
+	fh.Size = maxAttachmentFetchBytes + 1
+
+	msg := NewMessage()
+	err := msg.AttachMultipart(fh)
+	if err == nil || !strings.Contains(err.Error(), "exceeds limit") {
+		t.Errorf("AttachMultipart() error = %v, want size limit error", err)
+	}
+	if len(msg.Attachments) != 0 {
+		t.Errorf("Attachments length = %d, want 0", len(msg.Attachments))
+	}
+}
+
+func TestReset(t *testing.T) {
+	msg := validMessage().
+		AddCC("cc@example.com").
+		AddBCC("bcc@example.com").
+		SetHTMLBody("<p>Hi</p>").
+		AddHeader("X-Test", "1").
+		AttachFile("a.txt", "text/plain", []byte("data")).
+		AddTag("tag").
+		SetCategory("billing").
+		RejectDuplicateRecipients(true)
+	toArray := &msg.To[:1][0]
+
+	if got := msg.Reset(); got != msg {
+		t.Error("Reset() did not return the same message")
+	}
+
+	payload, _ := json.Marshal(msg)
+	want, _ := json.Marshal(NewMessage())
+	if string(payload) != string(want) {
+		t.Errorf("Reset() payload = %s, want %s", payload, want)
+	}
+	if msg.rejectDuplicates || msg.stripTags {
+		t.Error("Reset() kept duplicate recipient settings")
+	}
+	if &msg.To[:1][0] != toArray {
+		t.Error("Reset() reallocated the To backing array")
+	}
+	if msg.To[:1][0] != "" {
+		t.Errorf("Reset() left stale recipient %q in backing array", msg.To[:1][0])
+	}
+
+	msg.SetSender("sender@example.com").AddTo("new@example.com").SetSubject("New").SetTextBody("Body")
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() after reuse error = %v", err)
+	}
+
+	var zero Message
+	if zero.Reset().To == nil {
+		t.Error("Reset() on zero message left To nil")
+	}
+}
+
+func TestSetMessageID(t *testing.T) {
+	msg := validMessage().
+		SetMessageID("order-1@mail.example.com").
+		SetMessageID("<order-2@mail.example.com>")
+
+	if len(msg.Headers) != 1 {
+		t.Fatalf("Headers length = %d, want 1", len(msg.Headers))
+	}
+	if msg.Headers[0].Header != "Message-ID" || msg.Headers[0].Value != "<order-2@mail.example.com>" {
+		t.Errorf("Header = %+v, want Message-ID <order-2@mail.example.com>", msg.Headers[0])
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	for _, id := range []string{"no-at-sign", "a b@example.com", "a@b@example.com", "a@example..com", "@example.com", "a\r\n@example.com"} {
+		err := validMessage().SetMessageID(id).Validate()
+		if err == nil || !strings.HasPrefix(err.Error(), "invalid message id") {
+			t.Errorf("Validate() with id %q error = %v, want invalid message id", id, err)
+		}
+	}
+}
+
+func TestAddToWithVars(t *testing.T) {
+	vars := map[string]interface{}{"first_name": "Jane", "orders": 3}
+	msg := validMessage().AddToWithVars("Jane Doe <jane@Example.com>", vars)
+	vars["first_name"] = "changed"
+
+	if got := msg.To[len(msg.To)-1]; got != "Jane Doe <jane@Example.com>" {
+		t.Errorf("To = %q, want recipient added", got)
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	payload, _ := json.Marshal(msg)
+	want := `"recipient_variables":{"jane@example.com":{"first_name":"Jane","orders":3}}`
+	if !strings.Contains(string(payload), want) {
+		t.Errorf("Payload = %s, want %s", payload, want)
+	}
+
+	clone := msg.Clone()
+	clone.RecipientVariables["other@example.com"] = nil
+	if _, ok := msg.RecipientVariables["other@example.com"]; ok {
+		t.Error("Clone() shares RecipientVariables with the original")
+	}
+
+	msg.RecipientVariables["stranger@example.com"] = map[string]interface{}{"x": 1}
+	err := msg.Validate()
+	if err == nil || err.Error() != "recipient variables for stranger@example.com do not match any recipient" {
+		t.Errorf("Validate() error = %v, want unmatched recipient variables error", err)
+	}
+}
+
+func TestAttachment_Size(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 4, 5, 1000} {
+		msg := NewMessage().AttachFile("a.bin", "application/octet-stream", make([]byte, n))
+		if got := msg.Attachments[0].Size(); got != int64(n) {
+			t.Errorf("Size() for %d bytes = %d", n, got)
+		}
+	}
+}
+
+func TestAttachFileWrapped(t *testing.T) {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	msg := validMessage().AttachFileWrapped("a.bin", "application/octet-stream", data)
+	att := msg.Attachments[0]
+
+	lines := strings.Split(att.Data, "\r\n")
+	for i, line := range lines {
+		if len(line) > 76 || (i < len(lines)-1 && len(line) != 76) {
+			t.Errorf("line %d has %d characters, want 76 (at most 76 for the last line)", i, len(line))
+		}
+	}
+	if strings.Contains(strings.ReplaceAll(att.Data, "\r\n", ""), "\n") {
+		t.Error("Data contains a bare line feed")
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(att.Data, "\r\n", ""))
+	if err != nil || !bytes.Equal(decoded, data) {
+		t.Errorf("decoded data does not round-trip, error = %v", err)
+	}
+	if got := att.Size(); got != int64(len(data)) {
+		t.Errorf("Size() = %d, want %d", got, len(data))
+	}
+	payload, _ := json.Marshal(msg)
+	if got := msg.EstimatedSize(); got != int64(len(payload)) {
+		t.Errorf("EstimatedSize() = %d, want %d", got, len(payload))
+	}
+
+	short := NewMessage().AttachFileWrapped("a.txt", "text/plain", []byte("hi"))
+	if got := short.Attachments[0].Data; got != "aGk=" {
+		t.Errorf("Data = %q, want %q", got, "aGk=")
+	}
+}
+
+func TestSetInReplyToAndAddReference(t *testing.T) {
+	msg := validMessage().
+		SetInReplyTo("parent@example.com").
+		AddReference("<root@example.com>").
+		AddReference("parent@example.com")
+
+	if got, _ := msg.GetHeader("In-Reply-To"); got != "<parent@example.com>" {
+		t.Errorf("In-Reply-To = %q, want %q", got, "<parent@example.com>")
+	}
+	if got, _ := msg.GetHeader("References"); got != "<root@example.com> <parent@example.com>" {
+		t.Errorf("References = %q, want %q", got, "<root@example.com> <parent@example.com>")
+	}
+	if err := msg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	tests := []struct {
+		name string
+		msg  *Message
+		want string
+	}{
+		{"invalid in-reply-to", validMessage().SetInReplyTo("parent"), `invalid message id "<parent>" in In-Reply-To`},
+		{"invalid reference", validMessage().AddReference("ok@example.com").AddReference("bad"), `invalid message id "<bad>" in References`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.msg.Validate(); err == nil || err.Error() != tt.want {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddNameHelpers(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"Jane Doe", "jane@example.com", `"Jane Doe" <jane@example.com>`},
+		{"Doe, Jane", "jane@example.com", `"Doe, Jane" <jane@example.com>`},
+		{`Jane "JD" Doe`, "jane@example.com", `"Jane \"JD\" Doe" <jane@example.com>`},
+		{"Jürgen", "juergen@example.com", "=?utf-8?q?J=C3=BCrgen?= <juergen@example.com>"},
+		{"", "plain@example.com", "plain@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := NewMessage().AddToName(tt.name, tt.email).AddCCName(tt.name, tt.email).AddBCCName(tt.name, tt.email)
+			for field, list := range map[string][]string{"To": msg.To, "CC": msg.CC, "BCC": msg.BCC} {
+				if len(list) != 1 || list[0] != tt.want {
+					t.Errorf("%s = %v, want [%s]", field, list, tt.want)
+					continue
+				}
+				addr, err := mail.ParseAddress(list[0])
+				if err != nil {
+					t.Errorf("%s address %q does not parse: %v", field, list[0], err)
+					continue
+				}
+				if addr.Name != tt.name || addr.Address != tt.email {
+					t.Errorf("%s parsed = %q <%s>, want %q <%s>", field, addr.Name, addr.Address, tt.name, tt.email)
+				}
+			}
+		})
+	}
+}
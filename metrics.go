@@ -0,0 +1,29 @@
+package sendamatic
+
+import (
+	"errors"
+	"time"
+)
+
+// Metrics receives an observation after every call to Send. Implementations can
+// adapt it to Prometheus, OpenTelemetry or any other metrics system.
+//
+// ObserveSend is called on every exit path of Send, including nil-message and
+// validation failures, network errors and API errors. statusCode is the HTTP status
+// returned by the API, or 0 if no response was received. Implementations must be
+// safe for concurrent use if the Client is shared between goroutines.
+type Metrics interface {
+	ObserveSend(duration time.Duration, statusCode int, err error)
+}
+
+// observedStatusCode extracts the HTTP status code to report for a finished Send call.
+func observedStatusCode(resp *SendResponse, err error) int {
+	if resp != nil {
+		return resp.StatusCode
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type observation struct {
+	duration   time.Duration
+	statusCode int
+	err        error
+}
+
+type recordingMetrics struct {
+	observations []observation
+}
+
+func (m *recordingMetrics) ObserveSend(duration time.Duration, statusCode int, err error) {
+	m.observations = append(m.observations, observation{duration, statusCode, err})
+}
+
+func TestWithMetrics_ObservesAllExitPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "user-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "Invalid API key"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	validMsg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("recipient@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	tests := []struct {
+		name       string
+		client     func(m Metrics) *Client
+		msg        *Message
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "success",
+			client:     func(m Metrics) *Client { return NewClient("user", "pass", WithBaseURL(server.URL), WithMetrics(m)) },
+			msg:        validMsg,
+			wantStatus: 200,
+		},
+		{
+			name:       "api error",
+			client:     func(m Metrics) *Client { return NewClient("user", "wrong", WithBaseURL(server.URL), WithMetrics(m)) },
+			msg:        validMsg,
+			wantStatus: 401,
+			wantErr:    true,
+		},
+		{
+			name:    "network error",
+			client:  func(m Metrics) *Client { return NewClient("user", "pass", WithBaseURL(closed.URL), WithMetrics(m)) },
+			msg:     validMsg,
+			wantErr: true,
+		},
+		{
+			name:    "validation error",
+			client:  func(m Metrics) *Client { return NewClient("user", "pass", WithBaseURL(server.URL), WithMetrics(m)) },
+			msg:     NewMessage(),
+			wantErr: true,
+		},
+		{
+			name:    "nil message",
+			client:  func(m Metrics) *Client { return NewClient("user", "pass", WithBaseURL(server.URL), WithMetrics(m)) },
+			msg:     nil,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := &recordingMetrics{}
+			client := tt.client(metrics)
+
+			_, err := client.Send(context.Background(), tt.msg)
+
+			if len(metrics.observations) != 1 {
+				t.Fatalf("Observations = %d, want 1", len(metrics.observations))
+			}
+			obs := metrics.observations[0]
+			if obs.statusCode != tt.wantStatus {
+				t.Errorf("statusCode = %d, want %d", obs.statusCode, tt.wantStatus)
+			}
+			if (obs.err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", obs.err, tt.wantErr)
+			}
+			if !errors.Is(obs.err, err) {
+				t.Errorf("Observed error %v differs from returned error %v", obs.err, err)
+			}
+		})
+	}
+}
+
+func TestWithMetrics_UsesClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	clock := func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls-1) * 250 * time.Millisecond)
+	}
+
+	metrics := &recordingMetrics{}
+	client := NewClient("user", "pass", WithMetrics(metrics), WithClock(clock))
+
+	client.Send(context.Background(), nil)
+
+	if len(metrics.observations) != 1 {
+		t.Fatalf("Observations = %d, want 1", len(metrics.observations))
+	}
+	if got := metrics.observations[0].duration; got != 250*time.Millisecond {
+		t.Errorf("duration = %v, want 250ms", got)
+	}
+}
@@ -0,0 +1,28 @@
+package sendamatic
+
+import (
+	"context"
+	"net/http"
+)
+
+// SendFunc is the signature of Client.Send, used as the unit of composition for middleware.
+type SendFunc func(ctx context.Context, msg *Message) (*SendResponse, error)
+
+// Middleware wraps a SendFunc with cross-cutting behavior such as audit logging,
+// scrubbing personal data or feature flags. A middleware may inspect or replace the
+// message before calling next, inspect the result afterwards, or short-circuit by
+// returning without calling next. Middleware must not modify the *Message it receives
+// in place, since Send never modifies the caller's message; use Message.Clone instead.
+type Middleware func(next SendFunc) SendFunc
+
+// sendChain returns a SendFunc that runs the client's middleware in registration order
+// around send with the given extra request headers.
+func (c *Client) sendChain(header http.Header) SendFunc {
+	next := SendFunc(func(ctx context.Context, msg *Message) (*SendResponse, error) {
+		return c.send(ctx, msg, header)
+	})
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+	return next
+}
@@ -0,0 +1,92 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithMiddleware_Order(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next SendFunc) SendFunc {
+			return func(ctx context.Context, msg *Message) (*SendResponse, error) {
+				calls = append(calls, name+" before")
+				resp, err := next(ctx, msg)
+				calls = append(calls, name+" after")
+				return resp, err
+			}
+		}
+	}
+
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithMiddleware(record("first"), record("second")),
+		WithMiddleware(record("third")))
+
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	want := []string{"first before", "second before", "third before", "third after", "second after", "first after"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	errBlocked := errors.New("blocked by feature flag")
+	metrics := &recordingMetrics{}
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithMetrics(metrics),
+		WithMiddleware(func(next SendFunc) SendFunc {
+			return func(ctx context.Context, msg *Message) (*SendResponse, error) {
+				return nil, errBlocked
+			}
+		}))
+
+	if _, err := client.Send(context.Background(), validMessage()); !errors.Is(err, errBlocked) {
+		t.Errorf("Send() error = %v, want %v", err, errBlocked)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("requests = %d, want 0", got)
+	}
+	if got := len(metrics.observations); got != 1 {
+		t.Errorf("observations = %d, want 1", got)
+	}
+}
+
+func TestWithMiddleware_IdempotencyKeyPreserved(t *testing.T) {
+	var key atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key.Store(r.Header.Get("Idempotency-Key"))
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	passthrough := func(next SendFunc) SendFunc { return next }
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithMiddleware(passthrough))
+
+	if _, err := client.SendWithIdempotencyKey(context.Background(), validMessage(), "key-1"); err != nil {
+		t.Fatalf("SendWithIdempotencyKey() error = %v", err)
+	}
+	if got := key.Load(); got != "key-1" {
+		t.Errorf("Idempotency-Key = %v, want %q", got, "key-1")
+	}
+}
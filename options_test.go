@@ -1,7 +1,19 @@
 package sendamatic
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -152,3 +164,591 @@ func TestWithBaseURL_VariousURLs(t *testing.T) {
 		})
 	}
 }
+
+func TestWithCompression(t *testing.T) {
+	client := NewClient("user", "pass", WithCompression())
+
+	if !client.compress {
+		t.Error("compress = false, want true")
+	}
+}
+
+func TestWithMaxResponseBytes(t *testing.T) {
+	client := NewClient("user", "pass")
+	if client.maxResponseBytes != defaultMaxResponseBytes {
+		t.Errorf("maxResponseBytes = %d, want %d", client.maxResponseBytes, defaultMaxResponseBytes)
+	}
+
+	client = NewClient("user", "pass", WithMaxResponseBytes(1024))
+	if client.maxResponseBytes != 1024 {
+		t.Errorf("maxResponseBytes = %d, want 1024", client.maxResponseBytes)
+	}
+
+	for _, n := range []int64{0, -1} {
+		client = NewClient("user", "pass", WithMaxResponseBytes(n))
+		if client.maxResponseBytes != defaultMaxResponseBytes {
+			t.Errorf("WithMaxResponseBytes(%d): maxResponseBytes = %d, want default", n, client.maxResponseBytes)
+		}
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	customTransport := &http.Transport{
+		MaxIdleConns: 42,
+	}
+
+	client := NewClient("user", "pass",
+		WithTimeout(15*time.Second),
+		WithTransport(customTransport),
+	)
+
+	if client.httpClient.Transport != customTransport {
+		t.Error("Transport not set to custom transport")
+	}
+
+	if client.httpClient.Timeout != 15*time.Second {
+		t.Errorf("httpClient.Timeout = %v, want 15s", client.httpClient.Timeout)
+	}
+}
+
+func TestWithTransport_CallerClient(t *testing.T) {
+	callerTransport := &http.Transport{}
+	callerClient := &http.Client{Transport: callerTransport}
+	customTransport := &http.Transport{}
+
+	client := NewClient("user", "pass",
+		WithHTTPClient(callerClient),
+		WithTransport(customTransport),
+	)
+
+	if client.httpClient.Transport != customTransport {
+		t.Error("Transport not set to custom transport")
+	}
+	if callerClient.Transport != callerTransport {
+		t.Error("caller's http.Client was modified")
+	}
+}
+
+func TestWithRequestTimeout(t *testing.T) {
+	client := NewClient("user", "pass", WithRequestTimeout(5*time.Second))
+
+	if client.requestTimeout != 5*time.Second {
+		t.Errorf("requestTimeout = %v, want 5s", client.requestTimeout)
+	}
+}
+
+func TestWithInsecureSkipVerify(t *testing.T) {
+	client := NewClient("user", "pass", WithInsecureSkipVerify())
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport type = %T, want *http.Transport", client.httpClient.Transport)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify not enabled on default transport")
+	}
+}
+
+func TestWithInsecureSkipVerify_DoesNotWeakenCustomClient(t *testing.T) {
+	customTransport := &http.Transport{}
+	customClient := &http.Client{Transport: customTransport}
+
+	client := NewClient("user", "pass", WithInsecureSkipVerify(), WithHTTPClient(customClient))
+	if customTransport.TLSClientConfig != nil {
+		t.Error("Custom client's transport was modified")
+	}
+
+	client = NewClient("user", "pass", WithTransport(customTransport), WithInsecureSkipVerify())
+	if client.httpClient.Transport != customTransport || customTransport.TLSClientConfig != nil {
+		t.Error("Custom transport was modified or replaced")
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	fixed := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := NewClient("user", "pass", WithClock(func() time.Time { return fixed }))
+
+	if got := client.now(); !got.Equal(fixed) {
+		t.Errorf("now() = %v, want %v", got, fixed)
+	}
+
+	client = NewClient("user", "pass")
+	if client.now == nil {
+		t.Error("now is nil, want time.Now by default")
+	}
+
+	client = NewClient("user", "pass", WithClock(nil))
+	if client.now == nil {
+		t.Error("now is nil after WithClock(nil), want time.Now")
+	}
+}
+
+func TestWithConnectionPoolTuning(t *testing.T) {
+	client := NewClient("user", "pass", WithConnectionPoolTuning(200, 50, 45*time.Second))
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport type = %T, want *http.Transport", client.httpClient.Transport)
+	}
+	if transport == http.DefaultTransport {
+		t.Fatal("http.DefaultTransport must not be modified")
+	}
+	if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 50 || transport.IdleConnTimeout != 45*time.Second {
+		t.Errorf("Pool settings = %d/%d/%v, want 200/50/45s",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+}
+
+func TestWithConnectionPoolTuning_CallerTransport(t *testing.T) {
+	userTransport := &http.Transport{MaxIdleConns: 1}
+	client := NewClient("user", "pass",
+		WithTransport(userTransport),
+		WithConnectionPoolTuning(200, 50, 45*time.Second))
+
+	if userTransport.MaxIdleConns != 1 {
+		t.Errorf("caller transport MaxIdleConns = %d, want it unchanged", userTransport.MaxIdleConns)
+	}
+	if transport := client.httpClient.Transport.(*http.Transport); transport == userTransport || transport.MaxIdleConns != 200 {
+		t.Errorf("Transport = %p with MaxIdleConns %d, want a tuned copy", transport, transport.MaxIdleConns)
+	}
+
+	defaultIdle := http.DefaultTransport.(*http.Transport).MaxIdleConns
+	NewClient("user", "pass",
+		WithTransport(http.DefaultTransport),
+		WithConnectionPoolTuning(defaultIdle+1, 50, 45*time.Second))
+	if got := http.DefaultTransport.(*http.Transport).MaxIdleConns; got != defaultIdle {
+		t.Errorf("http.DefaultTransport MaxIdleConns = %d, want %d", got, defaultIdle)
+	}
+
+	hc := &http.Client{}
+	client = NewClient("user", "pass", WithHTTPClient(hc), WithConnectionPoolTuning(200, 50, 45*time.Second))
+	if hc.Transport != nil {
+		t.Errorf("caller http.Client Transport = %T, want nil", hc.Transport)
+	}
+	if client.httpClient == hc {
+		t.Error("caller http.Client must not be used for a tuned transport")
+	}
+}
+
+func TestWithConnectionPoolTuning_CombinedWithInsecureSkipVerify(t *testing.T) {
+	client := NewClient("user", "pass",
+		WithConnectionPoolTuning(10, 10, time.Second),
+		WithInsecureSkipVerify(),
+	)
+
+	transport := client.httpClient.Transport.(*http.Transport)
+	if transport.MaxIdleConns != 10 {
+		t.Errorf("MaxIdleConns = %d, want 10", transport.MaxIdleConns)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify not enabled on library-created transport")
+	}
+}
+
+func TestWithTransportTimeouts(t *testing.T) {
+	client := NewClient("user", "pass",
+		WithDialTimeout(2*time.Second),
+		WithTLSHandshakeTimeout(3*time.Second),
+		WithIdleTimeout(4*time.Second),
+	)
+
+	transport, ok := client.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport type = %T, want *http.Transport", client.httpClient.Transport)
+	}
+	if transport == http.DefaultTransport {
+		t.Fatal("http.DefaultTransport must not be modified")
+	}
+	if transport.DialContext == nil {
+		t.Error("DialContext = nil, want dialer with timeout")
+	}
+	if transport.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("TLSHandshakeTimeout = %v, want 3s", transport.TLSHandshakeTimeout)
+	}
+	if transport.IdleConnTimeout != 4*time.Second {
+		t.Errorf("IdleConnTimeout = %v, want 4s", transport.IdleConnTimeout)
+	}
+}
+
+func TestWithDialTimeout_Dials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithDialTimeout(time.Second))
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+}
+
+func TestWithTransportTimeouts_CallerTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	var dials atomic.Int32
+	var dialer net.Dialer
+	userTransport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials.Add(1)
+			if _, ok := ctx.Deadline(); !ok {
+				t.Error("dial context has no deadline")
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+		IdleConnTimeout: time.Minute,
+	}
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithTransport(userTransport),
+		WithDialTimeout(time.Second),
+		WithTLSHandshakeTimeout(2*time.Second),
+		WithIdleTimeout(3*time.Second))
+
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if dials.Load() != 1 {
+		t.Errorf("custom dialer calls = %d, want 1", dials.Load())
+	}
+	if userTransport.TLSHandshakeTimeout != 0 || userTransport.IdleConnTimeout != time.Minute {
+		t.Error("caller transport was modified")
+	}
+}
+
+func TestWithBaseURL_Normalization(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"scheme and host", "http://host", "http://host", false},
+		{"trailing slash", "https://host/", "https://host", false},
+		{"multiple trailing slashes", "https://host:8443//", "https://host:8443", false},
+		{"path prefix", "https://host/api/", "https://host/api", false},
+		{"bare host with slash", "host/", "host", true},
+		{"bare host", "host", "host", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient("user", "pass", WithBaseURL(tt.url))
+
+			if client.baseURL != tt.want {
+				t.Errorf("baseURL = %q, want %q", client.baseURL, tt.want)
+			}
+			if (client.baseURLErr != nil) != tt.wantErr {
+				t.Errorf("baseURLErr = %v, wantErr %v", client.baseURLErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithValidationDisabled(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	msg := NewMessage().SetSender("sender@example.com").AddTo("recipient@example.com")
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+	if _, err := client.Send(context.Background(), msg); err == nil {
+		t.Error("Send() without option error = nil, want validation error")
+	}
+	if calls != 0 {
+		t.Errorf("requests without option = %d, want 0", calls)
+	}
+
+	client = NewClient("user", "pass", WithBaseURL(server.URL), WithValidationDisabled())
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() with option error = %v, want nil", err)
+	}
+	if calls != 1 {
+		t.Errorf("requests with option = %d, want 1", calls)
+	}
+
+	if _, err := client.Send(context.Background(), nil); !errors.Is(err, ErrNilMessage) {
+		t.Errorf("Send(nil) error = %v, want ErrNilMessage", err)
+	}
+}
+
+func TestWithHTMLEscapingDisabled(t *testing.T) {
+	const html = `<p>Tom & Jerry > "Friends"</p>`
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantRawTag bool
+	}{
+		{"default escapes", nil, false},
+		{"escaping disabled", []Option{WithHTMLEscapingDisabled()}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+			}))
+			defer server.Close()
+
+			client := NewClient("user", "pass", append([]Option{WithBaseURL(server.URL)}, tt.opts...)...)
+			if _, err := client.Send(context.Background(), validMessage().SetHTMLBody(html)); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if got := strings.Contains(string(body), "<p>"); got != tt.wantRawTag {
+				t.Errorf("payload contains raw <p> = %v, want %v: %s", got, tt.wantRawTag, body)
+			}
+			if bytes.HasSuffix(body, []byte("\n")) {
+				t.Error("payload ends with a newline")
+			}
+
+			var decoded Message
+			if err := json.Unmarshal(body, &decoded); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if decoded.HTMLBody != html {
+				t.Errorf("HTMLBody = %q, want %q", decoded.HTMLBody, html)
+			}
+		})
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer proxy.Close()
+
+	client := NewClient("user", "pass",
+		WithBaseURL("http://api.example.invalid"),
+		WithProxy(proxy.URL),
+		WithTimeout(5*time.Second))
+
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want %v", client.httpClient.Timeout, 5*time.Second)
+	}
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if proxiedHost != "api.example.invalid" {
+		t.Errorf("proxied host = %q, want %q", proxiedHost, "api.example.invalid")
+	}
+}
+
+func TestWithProxy_Invalid(t *testing.T) {
+	for _, proxyURL := range []string{"", "proxy:3128", "ftp://proxy:21", "http://%zz"} {
+		client := NewClient("user", "pass", WithProxy(proxyURL))
+		_, err := client.Send(context.Background(), validMessage())
+		if err == nil || !strings.HasPrefix(err.Error(), "invalid proxy URL") {
+			t.Errorf("Send() with proxy %q error = %v, want invalid proxy URL error", proxyURL, err)
+		}
+	}
+}
+
+func TestWithProxy_CallerTransport(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer proxy.Close()
+
+	for _, order := range []string{"proxy first", "transport first"} {
+		userTransport := &http.Transport{}
+		opts := []Option{WithProxy(proxy.URL), WithTransport(userTransport)}
+		if order == "transport first" {
+			opts[0], opts[1] = opts[1], opts[0]
+		}
+		client := NewClient("user", "pass", append(opts, WithBaseURL("http://api.example.invalid"))...)
+
+		if _, err := client.Send(context.Background(), validMessage()); err != nil {
+			t.Errorf("%s: Send() error = %v", order, err)
+		}
+		if userTransport.Proxy != nil {
+			t.Errorf("%s: caller transport was modified", order)
+		}
+	}
+	if got := proxied.Load(); got != 2 {
+		t.Errorf("proxied requests = %d, want 2", got)
+	}
+}
+
+func TestWithProxy_UnsupportedTransport(t *testing.T) {
+	transport := &closeTrackingTransport{RoundTripper: http.DefaultTransport}
+	client := NewClient("user", "pass", WithProxy("http://proxy.internal:3128"), WithTransport(transport))
+
+	_, err := client.Send(context.Background(), validMessage())
+	if err == nil || !strings.Contains(err.Error(), "cannot use proxy") {
+		t.Errorf("Send() error = %v, want proxy error", err)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"recipient@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	cfg := &tls.Config{RootCAs: pool}
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithTLSConfig(cfg))
+	if _, err := client.Send(context.Background(), validMessage()); err != nil {
+		t.Errorf("Send() with trusted CA error = %v, want nil", err)
+	}
+	if client.tunableTransport().TLSClientConfig == cfg {
+		t.Error("TLS config was not cloned")
+	}
+
+	client = NewClient("user", "pass", WithBaseURL(server.URL))
+	if _, err := client.Send(context.Background(), validMessage()); err == nil {
+		t.Error("Send() without trusted CA error = nil, want certificate error")
+	}
+}
+
+func TestWithTLSConfig_DoesNotOverrideCustomClient(t *testing.T) {
+	customTransport := &http.Transport{}
+	customClient := &http.Client{Transport: customTransport}
+
+	NewClient("user", "pass", WithTLSConfig(&tls.Config{}), WithHTTPClient(customClient))
+	if customTransport.TLSClientConfig != nil {
+		t.Error("Custom client's transport was modified")
+	}
+
+	client := NewClient("user", "pass", WithTLSConfig(&tls.Config{ServerName: "proxy"}), WithInsecureSkipVerify())
+	cfg := client.tunableTransport().TLSClientConfig
+	if cfg == nil || cfg.ServerName != "proxy" || !cfg.InsecureSkipVerify {
+		t.Errorf("TLSClientConfig = %+v, want ServerName proxy with InsecureSkipVerify", cfg)
+	}
+}
+
+func TestWithMaxRecipients(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	msg := validMessage()
+	for i := len(msg.To); i < 300; i++ {
+		msg.AddTo(fmt.Sprintf("user%d@example.com", i))
+	}
+	if err := msg.Validate(); err == nil || err.Error() != "maximum 255 recipients allowed" {
+		t.Errorf("Validate() error = %v, want default limit error", err)
+	}
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithMaxRecipients(500))
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() with raised limit error = %v, want nil", err)
+	}
+
+	client = NewClient("user", "pass", WithBaseURL(server.URL), WithMaxRecipients(10))
+	_, err := client.Send(context.Background(), validMessage().AddTo("a@example.com").AddTo("b@example.com"))
+	if err != nil {
+		t.Errorf("Send() below lowered limit error = %v, want nil", err)
+	}
+	_, err = client.Send(context.Background(), msg)
+	if err == nil || err.Error() != "message validation failed: maximum 10 recipients allowed" {
+		t.Errorf("Send() above lowered limit error = %v, want limit error", err)
+	}
+
+	if client := NewClient("user", "pass", WithMaxRecipients(0)); client.maxRecipients != maxRecipients {
+		t.Errorf("maxRecipients = %d, want default %d", client.maxRecipients, maxRecipients)
+	}
+}
+
+func TestWithMaxAttachmentSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithMaxAttachmentSize(100))
+
+	msg := validMessage().AttachFile("small.txt", "text/plain", make([]byte, 100))
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() at limit error = %v, want nil", err)
+	}
+
+	msg.AttachFile("big.bin", "application/octet-stream", make([]byte, 101))
+	_, err := client.Send(context.Background(), msg)
+	want := "message validation failed: attachment 1: big.bin is 101 bytes, maximum is 100"
+	if err == nil || err.Error() != want {
+		t.Errorf("Send() above limit error = %v, want %q", err, want)
+	}
+}
+
+func TestWithFailOnPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok@example.com": [200, "msg-1"], "bad@example.com": [550, "msg-2"]}`))
+	}))
+	defer server.Close()
+
+	msg := validMessage()
+	msg.To = []string{"ok@example.com", "bad@example.com"}
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() without option error = %v, want nil", err)
+	}
+
+	client = NewClient("user", "pass", WithBaseURL(server.URL), WithFailOnPartialFailure())
+	resp, err := client.Send(context.Background(), msg)
+	if resp == nil {
+		t.Fatal("Send() response = nil, want response for inspection")
+	}
+	var recipientErr *RecipientError
+	if !errors.As(err, &recipientErr) || recipientErr.Email != "bad@example.com" {
+		t.Errorf("Send() error = %v, want RecipientError for bad@example.com", err)
+	}
+	if err.Error() != "1 recipient failed: bad@example.com (550)" {
+		t.Errorf("Send() error = %q", err.Error())
+	}
+}
+
+func TestWithAuthHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantHeader string
+		wantValue  string
+	}{
+		{"default", nil, "x-api-key", "user-pass"},
+		{"bearer", []Option{WithAuthHeader("Authorization", "Bearer %s")}, "Authorization", "Bearer user-pass"},
+		{"empty format", []Option{WithAuthHeader("X-Gateway-Key", "")}, "X-Gateway-Key", "user-pass"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				w.Write([]byte(`{}`))
+			}))
+			defer server.Close()
+
+			client := NewClient("user", "pass", append([]Option{WithBaseURL(server.URL)}, tt.opts...)...)
+			if _, err := client.Send(context.Background(), validMessage()); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if got := header.Get(tt.wantHeader); got != tt.wantValue {
+				t.Errorf("%s = %q, want %q", tt.wantHeader, got, tt.wantValue)
+			}
+			if tt.wantHeader != "x-api-key" && header.Get("x-api-key") != "" {
+				t.Error("x-api-key header sent alongside custom auth header")
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package sendamatic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledPayloadBytes is the buffer capacity above which payload buffers are not
+// returned to the pool, so that a single message with large attachments does not keep
+// its memory alive.
+const maxPooledPayloadBytes = 1 << 20
+
+// payloadPool holds reusable buffers for encoding request payloads.
+var payloadPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// payload is a request body shared by all attempts of a send. It is reference-counted:
+// the creator holds one reference and every request body returned by newBody holds
+// another until it is closed. A pooled buffer is only returned to payloadPool once all
+// references are released, because the HTTP transport may still read a request body
+// after the response has been received.
+//
+// If the message has attachment sources, the encoded bytes only hold the parts of the
+// payload around their data, and each source is streamed in at its offset in splits.
+type payload struct {
+	buf  *bytes.Buffer // pooled buffer, or nil if data is used
+	data []byte
+	refs atomic.Int32
+
+	sources []AttachmentSource
+	splits  []int
+	gzip    bool // compress the streamed body
+}
+
+// newPooledPayload returns an empty payload backed by a buffer from payloadPool.
+func newPooledPayload() *payload {
+	p := &payload{buf: payloadPool.Get().(*bytes.Buffer)}
+	p.buf.Reset()
+	p.refs.Store(1)
+	return p
+}
+
+// bytesPayload returns a payload for data that is not pooled.
+func bytesPayload(data []byte) *payload {
+	p := &payload{data: data}
+	p.refs.Store(1)
+	return p
+}
+
+// bytes returns the encoded payload. It must not be used after release.
+func (p *payload) bytes() []byte {
+	if p.buf != nil {
+		return p.buf.Bytes()
+	}
+	return p.data
+}
+
+// streamed reports whether the payload has attachment sources.
+func (p *payload) streamed() bool {
+	return len(p.sources) > 0
+}
+
+// length returns the size of the request body, or -1 if it is not known in advance
+// because a streamed body is compressed.
+func (p *payload) length() int64 {
+	if p.gzip {
+		return -1
+	}
+	n := int64(len(p.bytes()))
+	for _, src := range p.sources {
+		n += base64EncodedLen(src.Size())
+	}
+	return n
+}
+
+// newBody returns a request body reading the payload. It holds a reference that is
+// released when the body is closed, or for a streamed payload, once all of it has been
+// written.
+func (p *payload) newBody() io.ReadCloser {
+	p.refs.Add(1)
+	if !p.streamed() {
+		return &payloadBody{Reader: bytes.NewReader(p.bytes()), p: p}
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		defer p.release()
+		pw.CloseWithError(p.writeStream(pw))
+	}()
+	return pr
+}
+
+// writeStream writes a streamed payload to w, compressing it if requested.
+func (p *payload) writeStream(w io.Writer) error {
+	if !p.gzip {
+		return p.writeParts(w)
+	}
+	zw := gzip.NewWriter(w)
+	if err := p.writeParts(zw); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeParts writes the encoded bytes to w with the base64 data of each source
+// inserted at its offset.
+func (p *payload) writeParts(w io.Writer) error {
+	data, start := p.bytes(), 0
+	for i, src := range p.sources {
+		if _, err := w.Write(data[start:p.splits[i]]); err != nil {
+			return err
+		}
+		if err := streamSource(w, src); err != nil {
+			return err
+		}
+		start = p.splits[i]
+	}
+	_, err := w.Write(data[start:])
+	return err
+}
+
+// release drops a reference and returns the buffer to the pool after the last one.
+func (p *payload) release() {
+	if p.refs.Add(-1) == 0 && p.buf != nil && p.buf.Cap() <= maxPooledPayloadBytes {
+		payloadPool.Put(p.buf)
+	}
+}
+
+// payloadBody is a request body reading from a payload.
+type payloadBody struct {
+	*bytes.Reader
+	p    *payload
+	once sync.Once
+}
+
+// Close releases the body's reference to the payload. It may be called more than once.
+func (b *payloadBody) Close() error {
+	b.once.Do(b.p.release)
+	return nil
+}
+
+// encodeMessage encodes msg as the JSON request payload into a pooled buffer, escaping
+// HTML characters in strings unless disabled with WithHTMLEscapingDisabled. The caller
+// must release the returned payload.
+func (c *Client) encodeMessage(msg *Message) (*payload, error) {
+	p := newPooledPayload()
+	enc := json.NewEncoder(p.buf)
+	enc.SetEscapeHTML(!c.noHTMLEscape)
+	if len(msg.sources) > 0 {
+		if err := p.encodeStreamed(enc, msg); err != nil {
+			p.release()
+			return nil, err
+		}
+		return p, nil
+	}
+	if err := enc.Encode(msg); err != nil {
+		p.release()
+		return nil, err
+	}
+	// Encode terminates the value with a newline, which json.Marshal does not
+	p.buf.Truncate(p.buf.Len() - 1)
+	return p, nil
+}
+
+// encodeStreamed encodes msg with its attachments moved to the end of the object, so
+// that the attachment sources can follow the in-memory attachments. The offsets where
+// the sources' data belongs are recorded in splits.
+func (p *payload) encodeStreamed(enc *json.Encoder, msg *Message) error {
+	head := *msg
+	head.Attachments = nil
+	if err := enc.Encode(&head); err != nil {
+		return err
+	}
+	// Drop the closing brace and newline to append the attachments
+	p.buf.Truncate(p.buf.Len() - 2)
+
+	p.buf.WriteString(`,"attachments":[`)
+	for _, a := range msg.Attachments {
+		if err := enc.Encode(a); err != nil {
+			return err
+		}
+		p.buf.Truncate(p.buf.Len() - 1)
+		p.buf.WriteByte(',')
+	}
+	for i, src := range msg.sources {
+		if i > 0 {
+			p.buf.WriteByte(',')
+		}
+		p.buf.WriteString(`{"filename":`)
+		if err := enc.Encode(src.Filename()); err != nil {
+			return err
+		}
+		p.buf.Truncate(p.buf.Len() - 1)
+		p.buf.WriteString(`,"data":"`)
+		p.splits = append(p.splits, p.buf.Len())
+		p.buf.WriteString(`","mimetype":`)
+		if err := enc.Encode(src.MimeType()); err != nil {
+			return err
+		}
+		p.buf.Truncate(p.buf.Len() - 1)
+		p.buf.WriteString(`,"encoding":"base64"}`)
+	}
+	p.buf.WriteString("]}")
+	p.sources = msg.sources
+	return nil
+}
+
+// base64EncodedLen returns the length of the padded base64 encoding of n bytes.
+func base64EncodedLen(n int64) int64 {
+	return (n + 2) / 3 * 4
+}
@@ -1,18 +1,85 @@
 package sendamatic
 
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+)
+
+// HTTP status codes returned by the API, as found in SendResponse.StatusCode and
+// APIError.StatusCode. StatusAccepted is also the per-recipient status reported by
+// GetStatus for a recipient the API accepted for delivery.
+const (
+	StatusAccepted         = 200
+	StatusBadRequest       = 400
+	StatusUnauthorized     = 401
+	StatusForbidden        = 403
+	StatusValidationFailed = 422
+	StatusTooManyRequests  = 429
+	StatusServerError      = 500
+)
+
+// SMTP-style per-recipient status codes as reported by GetStatus and
+// RecipientError.StatusCode. Codes in the 4xx range are temporary failures and
+// codes in the 5xx range are permanent. Only StatusAccepted means a recipient was
+// accepted.
+const (
+	SMTPServiceUnavailable    = 421
+	SMTPMailboxBusy           = 450
+	SMTPLocalError            = 451
+	SMTPInsufficientStorage   = 452
+	SMTPMailboxUnavailable    = 550
+	SMTPUserNotLocal          = 551
+	SMTPExceededStorage       = 552
+	SMTPMailboxNameNotAllowed = 553
+	SMTPTransactionFailed     = 554
+)
+
+// StatusDescriptions maps per-recipient SMTP-style status codes to human-readable
+// descriptions. It is used by StatusDescription and may be extended by callers
+// during initialization.
+var StatusDescriptions = map[int]string{
+	StatusAccepted:            "accepted for delivery",
+	SMTPServiceUnavailable:    "service not available, try again later",
+	SMTPMailboxBusy:           "mailbox unavailable, try again later",
+	SMTPLocalError:            "local error in processing, try again later",
+	SMTPInsufficientStorage:   "insufficient system storage, try again later",
+	SMTPMailboxUnavailable:    "mailbox unavailable or message rejected",
+	SMTPUserNotLocal:          "user not local",
+	SMTPExceededStorage:       "exceeded storage allocation",
+	SMTPMailboxNameNotAllowed: "mailbox name not allowed",
+	SMTPTransactionFailed:     "transaction failed",
+}
+
 // SendResponse represents the response from a send email request.
 // It contains the overall HTTP status code and per-recipient delivery information
 // including individual status codes and message IDs.
 type SendResponse struct {
 	StatusCode int
-	Recipients map[string][2]interface{} // Email address -> [status code, message ID]
+	Recipients map[string][]interface{} // Email address -> [status code, message ID, ...]
+	Header     http.Header              `json:"-"` // HTTP response headers, e.g. rate limit information
+
+	// Suppressed lists the recipients removed before sending because they are on the
+	// suppression list configured with WithSuppressionList, as written in the message.
+	Suppressed []string `json:"-"`
+
+	// TestMode reports that the response was produced by a client configured with
+	// WithTestMode and that nothing was sent.
+	TestMode bool `json:"-"`
+}
+
+// RecipientResult holds the decoded delivery information for a single recipient.
+type RecipientResult struct {
+	Email     string
+	Status    int
+	MessageID string
 }
 
 // IsSuccess returns true if the email send request was successful (HTTP 200).
 // Note that this checks the overall request status; individual recipients
 // may still have failed. Use GetStatus to check per-recipient delivery status.
 func (r *SendResponse) IsSuccess() bool {
-	return r.StatusCode == 200
+	return r.StatusCode == StatusAccepted
 }
 
 // GetMessageID returns the message ID for a specific recipient email address.
@@ -41,3 +108,209 @@ func (r *SendResponse) GetStatus(email string) (int, bool) {
 	}
 	return 0, false
 }
+
+// FirstMessageID returns the message ID of a recipient in the response, which is
+// convenient for the common single-recipient send. The boolean is false if the
+// response contains no recipient with a message ID. With several recipients, which
+// message ID is returned is unspecified; use GetMessageID or Results instead.
+func (r *SendResponse) FirstMessageID() (string, bool) {
+	for email := range r.Recipients {
+		if msgID, ok := r.GetMessageID(email); ok {
+			return msgID, true
+		}
+	}
+	return "", false
+}
+
+// FailedRecipients returns the email addresses of all recipients whose delivery
+// status is not 200, sorted alphabetically. Recipients with a missing or malformed
+// status are reported as failed.
+func (r *SendResponse) FailedRecipients() []string {
+	var failed []string
+	for email := range r.Recipients {
+		if !r.accepted(email) {
+			failed = append(failed, email)
+		}
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// SuccessfulRecipients returns the email addresses of all recipients that were
+// accepted with status 200, sorted alphabetically. Together with FailedRecipients it
+// covers every recipient in the response.
+func (r *SendResponse) SuccessfulRecipients() []string {
+	var succeeded []string
+	for email := range r.Recipients {
+		if r.accepted(email) {
+			succeeded = append(succeeded, email)
+		}
+	}
+	sort.Strings(succeeded)
+	return succeeded
+}
+
+// TotalRecipients returns the number of recipients in the response.
+func (r *SendResponse) TotalRecipients() int {
+	return len(r.Recipients)
+}
+
+// SuccessCount returns the number of recipients accepted with status 200. It equals
+// the length of SuccessfulRecipients.
+func (r *SendResponse) SuccessCount() int {
+	count := 0
+	for email := range r.Recipients {
+		if r.accepted(email) {
+			count++
+		}
+	}
+	return count
+}
+
+// FailureCount returns the number of recipients that were not accepted. It equals
+// the length of FailedRecipients.
+func (r *SendResponse) FailureCount() int {
+	return r.TotalRecipients() - r.SuccessCount()
+}
+
+// accepted reports whether the recipient was accepted with status 200.
+func (r *SendResponse) accepted(email string) bool {
+	status, ok := r.GetStatus(email)
+	return ok && status == StatusAccepted
+}
+
+// AllSucceeded returns true only if the overall request succeeded (HTTP 200)
+// and every recipient in the response was accepted with status 200.
+func (r *SendResponse) AllSucceeded() bool {
+	return r.IsSuccess() && len(r.FailedRecipients()) == 0
+}
+
+// RecipientError returns a *RecipientError describing the delivery failure for the
+// given recipient, or nil if the recipient was accepted with status 200.
+// A recipient that is not present in the response is also reported as an error.
+func (r *SendResponse) RecipientError(email string) error {
+	status, ok := r.GetStatus(email)
+	if ok && status == StatusAccepted {
+		return nil
+	}
+	msgID, _ := r.GetMessageID(email)
+	return &RecipientError{
+		Email:      email,
+		StatusCode: status,
+		MessageID:  msgID,
+	}
+}
+
+// StatusDescription returns a human-readable description for a per-recipient status code.
+// Codes listed in StatusDescriptions return their mapped text; other codes fall back to
+// a generic description based on their class (4xx, 5xx).
+func StatusDescription(code int) string {
+	if desc, ok := StatusDescriptions[code]; ok {
+		return desc
+	}
+	switch {
+	case code >= 400 && code < 500:
+		return "temporary failure"
+	case code >= 500 && code < 600:
+		return "permanent failure"
+	}
+	return "unknown status"
+}
+
+// Describe returns the delivery status code and its human-readable description
+// for a specific recipient email address.
+// Returns the status code, description, and true if found, or 0, "" and false if not found.
+func (r *SendResponse) Describe(email string) (int, string, bool) {
+	status, ok := r.GetStatus(email)
+	if !ok {
+		return 0, "", false
+	}
+	return status, StatusDescription(status), true
+}
+
+// Results returns the decoded delivery information for all recipients, sorted by
+// email address. Recipients with a malformed status or message ID have the
+// respective field left at its zero value.
+func (r *SendResponse) Results() []RecipientResult {
+	results := make([]RecipientResult, 0, len(r.Recipients))
+	for email := range r.Recipients {
+		status, _ := r.GetStatus(email)
+		msgID, _ := r.GetMessageID(email)
+		results = append(results, RecipientResult{
+			Email:     email,
+			Status:    status,
+			MessageID: msgID,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Email < results[j].Email
+	})
+	return results
+}
+
+// Range calls f for each recipient in the response in the order returned by Results.
+// Iteration stops early if f returns false.
+func (r *SendResponse) Range(f func(email string, status int, messageID string) bool) {
+	for _, res := range r.Results() {
+		if !f(res.Email, res.Status, res.MessageID) {
+			return
+		}
+	}
+}
+
+// Error returns a RecipientErrors value listing every recipient that was not accepted,
+// e.g. "2 recipients failed: a@example.com (550), b@example.com (421)", or nil if all
+// recipients succeeded. Recipients are listed in the order of FailedRecipients.
+func (r *SendResponse) Error() error {
+	failed := r.FailedRecipients()
+	if len(failed) == 0 {
+		return nil
+	}
+	errs := make(RecipientErrors, len(failed))
+	for i, email := range failed {
+		errs[i] = r.RecipientError(email).(*RecipientError)
+	}
+	return errs
+}
+
+// Errors returns a map from recipient email address to a *RecipientError for every
+// recipient that was not accepted, which is convenient for updating per-recipient
+// state after a bulk send. Accepted recipients have no entry, so looking them up
+// yields a nil error. Statuses are decoded as by GetStatus; recipients with a missing
+// or malformed status are reported as failed. The map is empty if all recipients
+// succeeded.
+func (r *SendResponse) Errors() map[string]error {
+	errs := make(map[string]error)
+	for email := range r.Recipients {
+		if err := r.RecipientError(email); err != nil {
+			errs[email] = err
+		}
+	}
+	return errs
+}
+
+// JSON returns a stable, readable JSON representation of the response for forwarding
+// to other services, in the form
+//
+//	{"status":200,"recipients":[{"email":"a@example.com","status":200,"messageId":"msg-1"}]}
+//
+// Recipients are sorted by email address as in Results. The default encoding of
+// SendResponse via json.Marshal is unchanged.
+func (r *SendResponse) JSON() ([]byte, error) {
+	type recipientJSON struct {
+		Email     string `json:"email"`
+		Status    int    `json:"status"`
+		MessageID string `json:"messageId"`
+	}
+	out := struct {
+		Status     int             `json:"status"`
+		Recipients []recipientJSON `json:"recipients"`
+	}{
+		Status:     r.StatusCode,
+		Recipients: []recipientJSON{},
+	}
+	for _, res := range r.Results() {
+		out.Recipients = append(out.Recipients, recipientJSON(res))
+	}
+	return json.Marshal(out)
+}
@@ -2,6 +2,9 @@ package sendamatic
 
 import (
 	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -32,14 +35,14 @@ func TestSendResponse_IsSuccess(t *testing.T) {
 func TestSendResponse_GetMessageID(t *testing.T) {
 	tests := []struct {
 		name       string
-		recipients map[string][2]interface{}
+		recipients map[string][]interface{}
 		email      string
 		wantID     string
 		wantOK     bool
 	}{
 		{
 			name: "existing recipient",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test@example.com": {float64(200), "msg-12345"},
 			},
 			email:  "test@example.com",
@@ -48,7 +51,7 @@ func TestSendResponse_GetMessageID(t *testing.T) {
 		},
 		{
 			name: "non-existent recipient",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test@example.com": {float64(200), "msg-12345"},
 			},
 			email:  "other@example.com",
@@ -57,7 +60,7 @@ func TestSendResponse_GetMessageID(t *testing.T) {
 		},
 		{
 			name: "multiple recipients",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test1@example.com": {float64(200), "msg-11111"},
 				"test2@example.com": {float64(200), "msg-22222"},
 				"test3@example.com": {float64(400), "msg-33333"},
@@ -68,7 +71,7 @@ func TestSendResponse_GetMessageID(t *testing.T) {
 		},
 		{
 			name:       "empty recipients",
-			recipients: map[string][2]interface{}{},
+			recipients: map[string][]interface{}{},
 			email:      "test@example.com",
 			wantID:     "",
 			wantOK:     false,
@@ -96,14 +99,14 @@ func TestSendResponse_GetMessageID(t *testing.T) {
 func TestSendResponse_GetStatus(t *testing.T) {
 	tests := []struct {
 		name       string
-		recipients map[string][2]interface{}
+		recipients map[string][]interface{}
 		email      string
 		wantStatus int
 		wantOK     bool
 	}{
 		{
 			name: "existing recipient with success",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test@example.com": {float64(200), "msg-12345"},
 			},
 			email:      "test@example.com",
@@ -112,7 +115,7 @@ func TestSendResponse_GetStatus(t *testing.T) {
 		},
 		{
 			name: "existing recipient with error",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test@example.com": {float64(400), "msg-12345"},
 			},
 			email:      "test@example.com",
@@ -121,7 +124,7 @@ func TestSendResponse_GetStatus(t *testing.T) {
 		},
 		{
 			name: "non-existent recipient",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test@example.com": {float64(200), "msg-12345"},
 			},
 			email:      "other@example.com",
@@ -130,7 +133,7 @@ func TestSendResponse_GetStatus(t *testing.T) {
 		},
 		{
 			name: "multiple recipients",
-			recipients: map[string][2]interface{}{
+			recipients: map[string][]interface{}{
 				"test1@example.com": {float64(200), "msg-11111"},
 				"test2@example.com": {float64(550), "msg-22222"},
 				"test3@example.com": {float64(200), "msg-33333"},
@@ -141,7 +144,7 @@ func TestSendResponse_GetStatus(t *testing.T) {
 		},
 		{
 			name:       "empty recipients",
-			recipients: map[string][2]interface{}{},
+			recipients: map[string][]interface{}{},
 			email:      "test@example.com",
 			wantStatus: 0,
 			wantOK:     false,
@@ -173,7 +176,7 @@ func TestSendResponse_JSONUnmarshal(t *testing.T) {
 		"test2@example.com": [400, "msg-22222"]
 	}`
 
-	var recipients map[string][2]interface{}
+	var recipients map[string][]interface{}
 	err := json.Unmarshal([]byte(jsonResp), &recipients)
 	if err != nil {
 		t.Fatalf("Unmarshal failed: %v", err)
@@ -219,12 +222,38 @@ func TestSendResponse_JSONUnmarshal(t *testing.T) {
 	}
 }
 
+func TestSendResponse_JSONUnmarshal_ExtraTupleElements(t *testing.T) {
+	jsonResp := `{
+		"test1@example.com": [200, "msg-11111", "queued for delivery"],
+		"test2@example.com": [550]
+	}`
+
+	var recipients map[string][]interface{}
+	if err := json.Unmarshal([]byte(jsonResp), &recipients); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	resp := &SendResponse{StatusCode: 200, Recipients: recipients}
+
+	if status, ok := resp.GetStatus("test1@example.com"); !ok || status != 200 {
+		t.Errorf("GetStatus(test1) = %d, %v, want 200, true", status, ok)
+	}
+	if msgID, ok := resp.GetMessageID("test1@example.com"); !ok || msgID != "msg-11111" {
+		t.Errorf("GetMessageID(test1) = %q, %v, want %q, true", msgID, ok, "msg-11111")
+	}
+	if status, ok := resp.GetStatus("test2@example.com"); !ok || status != 550 {
+		t.Errorf("GetStatus(test2) = %d, %v, want 550, true", status, ok)
+	}
+	if msgID, ok := resp.GetMessageID("test2@example.com"); ok {
+		t.Errorf("GetMessageID(test2) = %q, %v, want \"\", false", msgID, ok)
+	}
+}
+
 func TestSendResponse_GetStatus_Float64Conversion(t *testing.T) {
 	// Explicitly test the float64 to int conversion
 	// This mimics how JSON unmarshaling works with numbers
 	resp := &SendResponse{
 		StatusCode: 200,
-		Recipients: map[string][2]interface{}{
+		Recipients: map[string][]interface{}{
 			"test@example.com": {float64(200.0), "msg-12345"},
 		},
 	}
@@ -243,7 +272,7 @@ func TestSendResponse_GetMessageID_InvalidType(t *testing.T) {
 	// Test behavior when message ID is not a string
 	resp := &SendResponse{
 		StatusCode: 200,
-		Recipients: map[string][2]interface{}{
+		Recipients: map[string][]interface{}{
 			"test@example.com": {float64(200), 12345}, // number instead of string
 		},
 	}
@@ -261,7 +290,7 @@ func TestSendResponse_GetStatus_InvalidType(t *testing.T) {
 	// Test behavior when status is not a number
 	resp := &SendResponse{
 		StatusCode: 200,
-		Recipients: map[string][2]interface{}{
+		Recipients: map[string][]interface{}{
 			"test@example.com": {"OK", "msg-12345"}, // string instead of number
 		},
 	}
@@ -274,3 +303,397 @@ func TestSendResponse_GetStatus_InvalidType(t *testing.T) {
 		t.Errorf("Expected 0, got %d", status)
 	}
 }
+
+func TestSendResponse_FailedRecipients(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"b@example.com": {float64(550), "msg-2"},
+			"a@example.com": {float64(200), "msg-1"},
+			"c@example.com": {"bogus", "msg-3"},
+		},
+	}
+
+	got := resp.FailedRecipients()
+	want := []string{"b@example.com", "c@example.com"}
+	if len(got) != len(want) {
+		t.Fatalf("FailedRecipients() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("FailedRecipients()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSendResponse_AllSucceeded(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		recipients map[string][]interface{}
+		want       bool
+	}{
+		{
+			name:       "all accepted",
+			statusCode: 200,
+			recipients: map[string][]interface{}{
+				"a@example.com": {float64(200), "msg-1"},
+				"b@example.com": {float64(200), "msg-2"},
+			},
+			want: true,
+		},
+		{
+			name:       "one rejected",
+			statusCode: 200,
+			recipients: map[string][]interface{}{
+				"a@example.com": {float64(200), "msg-1"},
+				"b@example.com": {float64(550), "msg-2"},
+			},
+			want: false,
+		},
+		{
+			name:       "overall failure",
+			statusCode: 500,
+			recipients: map[string][]interface{}{
+				"a@example.com": {float64(200), "msg-1"},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &SendResponse{StatusCode: tt.statusCode, Recipients: tt.recipients}
+			if got := resp.AllSucceeded(); got != tt.want {
+				t.Errorf("AllSucceeded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendResponse_RecipientError(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"ok@example.com":  {float64(200), "msg-1"},
+			"bad@example.com": {float64(550), "msg-2"},
+		},
+	}
+
+	if err := resp.RecipientError("ok@example.com"); err != nil {
+		t.Errorf("RecipientError(ok) = %v, want nil", err)
+	}
+
+	err := resp.RecipientError("bad@example.com")
+	if err == nil {
+		t.Fatal("RecipientError(bad) = nil, want error")
+	}
+	var recErr *RecipientError
+	if !errors.As(err, &recErr) {
+		t.Fatalf("Error type = %T, want *RecipientError", err)
+	}
+	if recErr.StatusCode != 550 {
+		t.Errorf("StatusCode = %d, want 550", recErr.StatusCode)
+	}
+	if !strings.Contains(err.Error(), "550") {
+		t.Errorf("Error message = %q, want to contain status 550", err.Error())
+	}
+
+	if err := resp.RecipientError("missing@example.com"); err == nil {
+		t.Error("RecipientError(missing) = nil, want error")
+	}
+}
+
+func TestStatusDescription(t *testing.T) {
+	tests := []struct {
+		code int
+		want string
+	}{
+		{200, "accepted for delivery"},
+		{421, "service not available, try again later"},
+		{550, "mailbox unavailable or message rejected"},
+		{250, "unknown status"},
+		{499, "temporary failure"},
+		{599, "permanent failure"},
+		{0, "unknown status"},
+	}
+
+	for _, tt := range tests {
+		if got := StatusDescription(tt.code); got != tt.want {
+			t.Errorf("StatusDescription(%d) = %q, want %q", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestSendResponse_Describe(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"bad@example.com": {float64(550), "msg-1"},
+		},
+	}
+
+	status, desc, ok := resp.Describe("bad@example.com")
+	if !ok {
+		t.Fatal("Expected to find recipient")
+	}
+	if status != 550 {
+		t.Errorf("status = %d, want 550", status)
+	}
+	if desc != StatusDescriptions[550] {
+		t.Errorf("description = %q, want %q", desc, StatusDescriptions[550])
+	}
+
+	if _, _, ok := resp.Describe("missing@example.com"); ok {
+		t.Error("Expected ok = false for missing recipient")
+	}
+}
+
+func TestSendResponse_Results(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"b@example.com": {float64(550), "msg-2"},
+			"a@example.com": {float64(200), "msg-1"},
+		},
+	}
+
+	got := resp.Results()
+	want := []RecipientResult{
+		{Email: "a@example.com", Status: 200, MessageID: "msg-1"},
+		{Email: "b@example.com", Status: 550, MessageID: "msg-2"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Results() length = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Results()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSendResponse_Range(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"a@example.com": {float64(200), "msg-1"},
+			"b@example.com": {float64(550), "msg-2"},
+			"c@example.com": {float64(200), "msg-3"},
+		},
+	}
+
+	var visited []string
+	resp.Range(func(email string, status int, messageID string) bool {
+		visited = append(visited, email)
+		return email != "b@example.com"
+	})
+
+	if len(visited) != 2 || visited[0] != "a@example.com" || visited[1] != "b@example.com" {
+		t.Errorf("Range visited %v, want [a@example.com b@example.com]", visited)
+	}
+}
+
+func TestSendResponse_Error(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"ok@example.com": {float64(200), "msg-1"},
+		},
+	}
+	if err := resp.Error(); err != nil {
+		t.Errorf("Error() = %v, want nil", err)
+	}
+
+	resp.Recipients["b@example.com"] = []interface{}{float64(421), "msg-2"}
+	resp.Recipients["a@example.com"] = []interface{}{float64(550), "msg-3"}
+
+	err := resp.Error()
+	if err == nil {
+		t.Fatal("Error() = nil, want error")
+	}
+	want := "2 recipients failed: a@example.com (550), b@example.com (421)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	var recErr *RecipientError
+	if !errors.As(err, &recErr) {
+		t.Fatal("errors.As failed to find *RecipientError")
+	}
+	if recErr.Email != "a@example.com" {
+		t.Errorf("First RecipientError email = %q, want a@example.com", recErr.Email)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Error("Recipient errors must not match *APIError")
+	}
+}
+
+func TestSendResponse_Errors(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"ok@example.com":        {float64(200), "msg-1"},
+			"bounced@example.com":   {float64(550), "msg-2"},
+			"malformed@example.com": {"200", "msg-3"},
+		},
+	}
+
+	errs := resp.Errors()
+	if len(errs) != 2 {
+		t.Fatalf("len(Errors()) = %d, want 2", len(errs))
+	}
+	if err := errs["ok@example.com"]; err != nil {
+		t.Errorf("Errors()[ok] = %v, want nil", err)
+	}
+
+	var recErr *RecipientError
+	if !errors.As(errs["bounced@example.com"], &recErr) {
+		t.Fatalf("Errors()[bounced] = %T, want *RecipientError", errs["bounced@example.com"])
+	}
+	if recErr.StatusCode != 550 || recErr.MessageID != "msg-2" {
+		t.Errorf("Errors()[bounced] = %+v, want status 550 and message ID msg-2", recErr)
+	}
+	if errs["malformed@example.com"] == nil {
+		t.Error("Errors()[malformed] = nil, want error")
+	}
+
+	resp.Recipients = map[string][]interface{}{"ok@example.com": {float64(200), "msg-1"}}
+	if errs := resp.Errors(); errs == nil || len(errs) != 0 {
+		t.Errorf("Errors() = %v, want empty map", errs)
+	}
+}
+
+func TestRecipientErrors_Single(t *testing.T) {
+	err := RecipientErrors{{Email: "a@example.com"}}
+	want := "1 recipient failed: a@example.com (no status)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestSendResponse_JSON(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"b@example.com": {float64(550), "msg-2"},
+			"a@example.com": {float64(200), "msg-1"},
+		},
+	}
+
+	got, err := resp.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	want := `{"status":200,"recipients":[` +
+		`{"email":"a@example.com","status":200,"messageId":"msg-1"},` +
+		`{"email":"b@example.com","status":550,"messageId":"msg-2"}]}`
+	if string(got) != want {
+		t.Errorf("JSON() = %s, want %s", got, want)
+	}
+
+	empty, _ := (&SendResponse{StatusCode: 200}).JSON()
+	if string(empty) != `{"status":200,"recipients":[]}` {
+		t.Errorf("JSON() empty = %s, want empty recipients array", empty)
+	}
+}
+
+func TestStatusConstants(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: StatusAccepted,
+		Recipients: map[string][]interface{}{
+			"ok@example.com":  {float64(200), "msg-1"},
+			"bad@example.com": {float64(550), "msg-2"},
+		},
+	}
+
+	if status, _ := resp.GetStatus("ok@example.com"); status != StatusAccepted {
+		t.Errorf("GetStatus(ok) = %d, want StatusAccepted", status)
+	}
+	if status, _ := resp.GetStatus("bad@example.com"); status != SMTPMailboxUnavailable {
+		t.Errorf("GetStatus(bad) = %d, want SMTPMailboxUnavailable", status)
+	}
+
+	apiErr := parseErrorResponse(422, []byte(`{"error": "invalid"}`)).(*APIError)
+	if apiErr.StatusCode != StatusValidationFailed {
+		t.Errorf("StatusCode = %d, want StatusValidationFailed", apiErr.StatusCode)
+	}
+}
+
+func TestSendResponse_Counts(t *testing.T) {
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: map[string][]interface{}{
+			"a@example.com": {float64(200), "msg-1"},
+			"b@example.com": {float64(550), "msg-2"},
+			"c@example.com": {float64(200), "msg-3"},
+			"d@example.com": {"malformed", nil},
+		},
+	}
+
+	if got := resp.TotalRecipients(); got != 4 {
+		t.Errorf("TotalRecipients() = %d, want 4", got)
+	}
+	if got := resp.SuccessCount(); got != 2 {
+		t.Errorf("SuccessCount() = %d, want 2", got)
+	}
+	if got := resp.FailureCount(); got != 2 {
+		t.Errorf("FailureCount() = %d, want 2", got)
+	}
+
+	wantSucceeded := []string{"a@example.com", "c@example.com"}
+	if got := resp.SuccessfulRecipients(); !reflect.DeepEqual(got, wantSucceeded) {
+		t.Errorf("SuccessfulRecipients() = %v, want %v", got, wantSucceeded)
+	}
+	if got := len(resp.FailedRecipients()); got != resp.FailureCount() {
+		t.Errorf("len(FailedRecipients()) = %d, want FailureCount() %d", got, resp.FailureCount())
+	}
+
+	empty := &SendResponse{StatusCode: 200}
+	if empty.TotalRecipients() != 0 || empty.SuccessCount() != 0 || empty.FailureCount() != 0 {
+		t.Error("counts of empty response are not zero")
+	}
+}
+
+func TestSendResponse_FirstMessageID(t *testing.T) {
+	tests := []struct {
+		name   string
+		resp   *SendResponse
+		wantID string
+		wantOK bool
+	}{
+		{
+			name: "single recipient",
+			resp: &SendResponse{Recipients: map[string][]interface{}{
+				"a@example.com": {float64(200), "msg-1"},
+			}},
+			wantID: "msg-1",
+			wantOK: true,
+		},
+		{
+			name:   "no recipients",
+			resp:   &SendResponse{},
+			wantID: "",
+			wantOK: false,
+		},
+		{
+			name: "missing message id",
+			resp: &SendResponse{Recipients: map[string][]interface{}{
+				"a@example.com": {float64(550), nil},
+			}},
+			wantID: "",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, ok := tt.resp.FirstMessageID()
+			if id != tt.wantID || ok != tt.wantOK {
+				t.Errorf("FirstMessageID() = %q, %v, want %q, %v", id, ok, tt.wantID, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// JitterMode selects how random jitter is applied to retry delays to avoid many
+// clients retrying in lockstep after an outage.
+type JitterMode int
+
+const (
+	// JitterNone uses retry delays exactly as returned by the retry policy. This is the default.
+	JitterNone JitterMode = iota
+	// JitterFull replaces each delay d with a random duration in [0, d].
+	JitterFull
+	// JitterEqual replaces each delay d with d/2 plus a random duration in [0, d/2].
+	JitterEqual
+)
+
+// RetryPolicy decides whether a failed send attempt should be retried.
+// It is called after every attempt with the 1-based attempt number and either the
+// HTTP response (whose body has already been read and closed) or the error that
+// prevented a response from being received. It returns whether to retry and how
+// long to wait before the next attempt.
+//
+// The policy is only consulted while the request context is still live; once the
+// context is canceled or its deadline is exceeded, Send returns without retrying.
+type RetryPolicy func(attempt int, resp *http.Response, err error) (retry bool, delay time.Duration)
+
+// isTransient reports whether an attempt failed with a transient error: a network
+// error, 429 Too Many Requests, or a 5xx status. The circuit breaker counts these as
+// failures of the API.
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// isSafeToRetry reports whether a failed attempt can be repeated without risking a
+// duplicate email. Sends are not idempotent, so this is only the case if the request
+// provably never reached the API because no connection could be established, or if
+// the API turned it away with 429 Too Many Requests or 503 Service Unavailable.
+// Timeouts, dropped connections and other 5xx responses are not safe, since the API
+// may already have accepted the message.
+func isSafeToRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return true
+		}
+		var opErr *net.OpError
+		return errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// maxBackoffDelay caps the delay of exponentialBackoffPolicy, unless the base delay
+// itself is longer.
+const maxBackoffDelay = 30 * time.Second
+
+// exponentialBackoffPolicy returns a RetryPolicy that retries failures that are safe
+// to retry up to maxRetries times, doubling the delay after each attempt starting at
+// baseDelay, up to maxBackoffDelay.
+func exponentialBackoffPolicy(maxRetries int, baseDelay time.Duration) RetryPolicy {
+	return func(attempt int, resp *http.Response, err error) (bool, time.Duration) {
+		if attempt > maxRetries || !isSafeToRetry(resp, err) {
+			return false, 0
+		}
+		return true, backoffDelay(baseDelay, attempt)
+	}
+}
+
+// backoffDelay returns baseDelay doubled attempt-1 times, capped at maxBackoffDelay or
+// baseDelay, whichever is longer. The cap also guards against overflow.
+func backoffDelay(baseDelay time.Duration, attempt int) time.Duration {
+	limit := max(maxBackoffDelay, baseDelay)
+	if shift := attempt - 1; shift < 63 && baseDelay <= limit>>shift {
+		return baseDelay << shift
+	}
+	return limit
+}
+
+// applyJitter returns the delay adjusted according to mode, using randN to draw a
+// random value in [0, n).
+func applyJitter(d time.Duration, mode JitterMode, randN func(n int64) int64) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	switch mode {
+	case JitterFull:
+		return time.Duration(randN(int64(d) + 1))
+	case JitterEqual:
+		half := d / 2
+		return half + time.Duration(randN(int64(d-half)+1))
+	default:
+		return d
+	}
+}
+
+// sleepContext waits for the given duration or until the context is done,
+// whichever happens first. It returns the context error if the context ended early.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
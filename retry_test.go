@@ -0,0 +1,305 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoffPolicy(t *testing.T) {
+	policy := exponentialBackoffPolicy(3, 100*time.Millisecond)
+
+	tests := []struct {
+		name      string
+		attempt   int
+		resp      *http.Response
+		err       error
+		wantRetry bool
+		wantDelay time.Duration
+	}{
+		{"connection refused", 1, nil, &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true, 100 * time.Millisecond},
+		{"dns failure", 1, nil, &net.DNSError{Err: "no such host", IsNotFound: true}, true, 100 * time.Millisecond},
+		{"service unavailable", 2, &http.Response{StatusCode: 503}, nil, true, 200 * time.Millisecond},
+		{"rate limited", 3, &http.Response{StatusCode: 429}, nil, true, 400 * time.Millisecond},
+		{"retries exhausted", 4, &http.Response{StatusCode: 503}, nil, false, 0},
+		{"connection reset after sending", 1, nil, &net.OpError{Op: "read", Err: syscall.ECONNRESET}, false, 0},
+		{"timeout", 1, nil, context.DeadlineExceeded, false, 0},
+		{"response too large", 1, nil, errors.New("response body exceeds limit of 10 bytes"), false, 0},
+		{"internal server error", 1, &http.Response{StatusCode: 500}, nil, false, 0},
+		{"bad gateway", 1, &http.Response{StatusCode: 502}, nil, false, 0},
+		{"client error", 1, &http.Response{StatusCode: 400}, nil, false, 0},
+		{"success", 1, &http.Response{StatusCode: 200}, nil, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, delay := policy(tt.attempt, tt.resp, tt.err)
+			if retry != tt.wantRetry {
+				t.Errorf("retry = %v, want %v", retry, tt.wantRetry)
+			}
+			if delay != tt.wantDelay {
+				t.Errorf("delay = %v, want %v", delay, tt.wantDelay)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{time.Second, 1, time.Second},
+		{time.Second, 5, 16 * time.Second},
+		{time.Second, 6, maxBackoffDelay},
+		{time.Second, 64, maxBackoffDelay},
+		{time.Second, 1000, maxBackoffDelay},
+		{time.Hour, 3, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoffDelay(tt.base, tt.attempt); got != tt.want {
+			t.Errorf("backoffDelay(%v, %d) = %v, want %v", tt.base, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Send_RetryPolicy(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": "Service unavailable"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"to@example.com": [200, "msg-12345"]}`))
+	}))
+	defer server.Close()
+
+	var attempts []int
+	policy := func(attempt int, resp *http.Response, err error) (bool, time.Duration) {
+		attempts = append(attempts, attempt)
+		return err != nil || resp.StatusCode >= 500, 0
+	}
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetryPolicy(policy))
+
+	resp, err := client.Send(context.Background(), validMessage())
+	if err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+	if !resp.IsSuccess() {
+		t.Error("Expected successful response")
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Server calls = %d, want 3", got)
+	}
+	if len(attempts) != 3 || attempts[0] != 1 || attempts[2] != 3 {
+		t.Errorf("Policy attempts = %v, want [1 2 3]", attempts)
+	}
+}
+
+func TestClient_Send_RetryExhausted(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error": "Service unavailable"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
+
+	_, err := client.Send(context.Background(), validMessage())
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Error type = %T, want *APIError", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Errorf("Server calls = %d, want 3", got)
+	}
+}
+
+func TestClient_Send_TransportErrorAttempts(t *testing.T) {
+	// A closed server refuses connections, so no request can have been sent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
+
+	resp, err := client.Send(context.Background(), validMessage())
+	if resp != nil {
+		t.Errorf("Send() response = %v, want nil", resp)
+	}
+
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("Error type = %T, want *TransportError", err)
+	}
+	if transportErr.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", transportErr.Attempts)
+	}
+	if !strings.HasSuffix(err.Error(), "(after 3 attempts)") {
+		t.Errorf("Error() = %q, want attempt count", err.Error())
+	}
+}
+
+func TestClient_Send_NoRetryAfterRequestSent(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Hijack() error = %v", err)
+			return
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
+
+	var transportErr *TransportError
+	if _, err := client.Send(context.Background(), validMessage()); !errors.As(err, &transportErr) {
+		t.Fatalf("Error type = %T, want *TransportError", err)
+	}
+	if got := atomic.LoadInt32(&calls); transportErr.Attempts != 1 || got != 1 {
+		t.Errorf("Attempts = %d, server calls = %d, want 1", transportErr.Attempts, got)
+	}
+}
+
+func TestClient_Send_RetryRespectsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(5, time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Send(ctx, validMessage())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline exceeded error, got: %v", err)
+	}
+	var transportErr *TransportError
+	if !errors.As(err, &transportErr) || transportErr.Attempts != 1 {
+		t.Errorf("Error = %#v, want *TransportError with 1 attempt", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Send took %v, want to abort with the context", elapsed)
+	}
+}
+
+func TestClient_Send_RetryDeadlineDuringBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// Ten retries 40ms apart are planned, but the deadline expires during the third wait
+	client := NewClient("user", "pass",
+		WithBaseURL(server.URL),
+		WithRetryPolicy(func(attempt int, resp *http.Response, err error) (bool, time.Duration) {
+			return attempt <= 10, 40 * time.Millisecond
+		}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Send(ctx, validMessage())
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("Send took %v, want to return promptly at the 100ms deadline", elapsed)
+	}
+	var transportErr *TransportError
+	if got := atomic.LoadInt32(&calls); !errors.As(err, &transportErr) || transportErr.Attempts != int(got) {
+		t.Errorf("Error = %v, want *TransportError with Attempts = %d server calls", err, got)
+	}
+	if got := atomic.LoadInt32(&calls); got < 2 || got > 4 {
+		t.Errorf("Server calls = %d, want 2 to 4 before the deadline", got)
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+	if err := sleepContext(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("sleepContext() error = %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := sleepContext(ctx, time.Minute); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("sleepContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("sleepContext took %v, want to return at the deadline", elapsed)
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sleepContext(canceled, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("sleepContext() with zero delay error = %v, want context.Canceled", err)
+	}
+}
+
+func TestApplyJitter(t *testing.T) {
+	maxRand := func(n int64) int64 { return n - 1 }
+	minRand := func(n int64) int64 { return 0 }
+
+	tests := []struct {
+		name  string
+		mode  JitterMode
+		randN func(int64) int64
+		want  time.Duration
+	}{
+		{"none", JitterNone, maxRand, 100 * time.Millisecond},
+		{"full min", JitterFull, minRand, 0},
+		{"full max", JitterFull, maxRand, 100 * time.Millisecond},
+		{"equal min", JitterEqual, minRand, 50 * time.Millisecond},
+		{"equal max", JitterEqual, maxRand, 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyJitter(100*time.Millisecond, tt.mode, tt.randN); got != tt.want {
+				t.Errorf("applyJitter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := applyJitter(0, JitterFull, maxRand); got != 0 {
+		t.Errorf("applyJitter(0) = %v, want 0", got)
+	}
+}
+
+func TestWithBackoffJitter(t *testing.T) {
+	client := NewClient("user", "pass")
+	if client.jitter != JitterNone {
+		t.Errorf("jitter = %v, want JitterNone by default", client.jitter)
+	}
+
+	client = NewClient("user", "pass", WithBackoffJitter(JitterEqual))
+	if client.jitter != JitterEqual {
+		t.Errorf("jitter = %v, want JitterEqual", client.jitter)
+	}
+}
@@ -0,0 +1,85 @@
+package sendamatic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// EmailSender is the interface for sending a Message. *Client implements it, so code
+// that sends email can depend on EmailSender and use FakeSender in tests.
+type EmailSender interface {
+	Send(ctx context.Context, msg *Message) (*SendResponse, error)
+}
+
+var _ EmailSender = (*Client)(nil)
+
+// FakeSender is an in-memory EmailSender for tests. It validates and records every
+// message it is asked to send instead of contacting the API. A FakeSender is safe for
+// concurrent use; the zero value is ready to use.
+//
+// Example:
+//
+//	fake := &sendamatic.FakeSender{}
+//	svc := NewSignupService(fake)
+//	svc.Register(ctx, "user@example.com")
+//	if len(fake.Messages()) != 1 { ... }
+type FakeSender struct {
+	// Err, if set, is returned by Send for every valid message. The message is
+	// recorded regardless.
+	Err error
+
+	mu       sync.Mutex
+	messages []*Message
+}
+
+var _ EmailSender = (*FakeSender)(nil)
+
+// Send validates msg like Client.Send does and records a copy of it. Unless Err is
+// set, it returns a successful SendResponse in which every recipient is accepted
+// with status 200 and a generated message ID.
+func (f *FakeSender) Send(ctx context.Context, msg *Message) (*SendResponse, error) {
+	if msg == nil {
+		return nil, ErrNilMessage
+	}
+	if err := msg.Validate(); err != nil {
+		return nil, fmt.Errorf("message validation failed: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.messages = append(f.messages, msg.Clone())
+	n := len(f.messages)
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	resp := &SendResponse{
+		StatusCode: 200,
+		Recipients: make(map[string][]interface{}),
+	}
+	for _, list := range [][]string{msg.To, msg.CC, msg.BCC} {
+		for _, email := range list {
+			resp.Recipients[email] = []interface{}{float64(200), fmt.Sprintf("fake-%d", n)}
+		}
+	}
+	return resp, nil
+}
+
+// Messages returns copies of all messages recorded so far, in the order they were sent.
+func (f *FakeSender) Messages() []*Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*Message{}, f.messages...)
+}
+
+// Reset discards all recorded messages.
+func (f *FakeSender) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = nil
+}
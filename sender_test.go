@@ -0,0 +1,69 @@
+package sendamatic
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFakeSender(t *testing.T) {
+	var sender EmailSender = &FakeSender{}
+	fake := sender.(*FakeSender)
+
+	msg := NewMessage().
+		SetSender("sender@example.com").
+		AddTo("to@example.com").
+		AddBCC("bcc@example.com").
+		SetSubject("Test").
+		SetTextBody("Body")
+
+	resp, err := sender.Send(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v, want nil", err)
+	}
+	if !resp.AllSucceeded() {
+		t.Error("Expected all recipients to succeed")
+	}
+	if msgID, ok := resp.GetMessageID("bcc@example.com"); !ok || msgID != "fake-1" {
+		t.Errorf("GetMessageID() = %q, %v, want fake-1, true", msgID, ok)
+	}
+
+	// Recorded messages are copies
+	msg.SetSubject("Changed")
+	recorded := fake.Messages()
+	if len(recorded) != 1 {
+		t.Fatalf("Messages() length = %d, want 1", len(recorded))
+	}
+	if recorded[0].Subject != "Test" {
+		t.Errorf("Recorded subject = %q, want %q", recorded[0].Subject, "Test")
+	}
+
+	fake.Reset()
+	if len(fake.Messages()) != 0 {
+		t.Error("Reset() did not clear recorded messages")
+	}
+}
+
+func TestFakeSender_Errors(t *testing.T) {
+	fake := &FakeSender{}
+
+	if _, err := fake.Send(context.Background(), nil); !errors.Is(err, ErrNilMessage) {
+		t.Errorf("Send(nil) error = %v, want ErrNilMessage", err)
+	}
+	if _, err := fake.Send(context.Background(), NewMessage()); err == nil {
+		t.Error("Send(invalid) error = nil, want validation error")
+	}
+	if len(fake.Messages()) != 0 {
+		t.Error("Invalid messages must not be recorded")
+	}
+
+	wantErr := &APIError{StatusCode: 500, Message: "boom"}
+	fake.Err = wantErr
+	_, err := fake.Send(context.Background(), validMessage())
+	if !errors.Is(err, wantErr) {
+		t.Errorf("Send() error = %v, want configured error", err)
+	}
+	if len(fake.Messages()) != 1 {
+		t.Error("Message must be recorded even when Err is set")
+	}
+}
@@ -0,0 +1,118 @@
+package sendamatic
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// AttachmentSource provides attachment content that is read when the message is sent
+// instead of being held in memory as base64 data. Send opens the source once per
+// request attempt and streams it, base64-encoded, into the request body, so memory use
+// stays constant regardless of the attachment size.
+//
+// Size must return the exact number of bytes that Open yields; it is used for the
+// request's Content-Length, for EstimatedSize and for WithMaxAttachmentSize. A send
+// fails if the content read does not match. Open may be called more than once, e.g.
+// when a request is retried, and must return the full content each time.
+type AttachmentSource interface {
+	Open() (io.ReadCloser, error)
+	Filename() string
+	MimeType() string
+	Size() int64
+}
+
+// AttachSource adds an attachment whose content is streamed from src at send time.
+// Use it instead of AttachFile for large files or when many messages with attachments
+// are in memory at the same time. Streamed attachments are sent after those added
+// with AttachFile, appear in the payload's "attachments" field like any other, and
+// are validated like them; they are not included in ToJSON, MarshalReadable or
+// FromMailMessage round trips. A nil src is ignored. Returns the message for method
+// chaining.
+//
+// Example:
+//
+//	src, err := sendamatic.FileSource("/var/reports/2024-q4.pdf", "application/pdf")
+//	if err != nil {
+//		return err
+//	}
+//	msg.AttachSource(src)
+func (m *Message) AttachSource(src AttachmentSource) *Message {
+	if src == nil {
+		return m
+	}
+	m.sources = append(m.sources, src)
+	return m
+}
+
+// Sources returns the attachment sources added with AttachSource.
+func (m *Message) Sources() []AttachmentSource {
+	return m.sources
+}
+
+// FileSource returns an AttachmentSource for the file at path, named after the last
+// element of the path. The file's size is read now, but its content is only read when
+// the message is sent, so the file must not change in between. If mimeType is empty,
+// application/octet-stream is used.
+func FileSource(path, mimeType string) (AttachmentSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat attachment: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("attachment %s is not a regular file", path)
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return &fileSource{path: path, mimeType: mimeType, size: info.Size()}, nil
+}
+
+// fileSource is the AttachmentSource returned by FileSource.
+type fileSource struct {
+	path     string
+	mimeType string
+	size     int64
+}
+
+// Open opens the file for reading.
+func (s *fileSource) Open() (io.ReadCloser, error) {
+	return os.Open(s.path)
+}
+
+// Filename returns the last element of the file's path.
+func (s *fileSource) Filename() string {
+	return filepath.Base(s.path)
+}
+
+// MimeType returns the MIME type given to FileSource.
+func (s *fileSource) MimeType() string {
+	return s.mimeType
+}
+
+// Size returns the file size determined by FileSource.
+func (s *fileSource) Size() int64 {
+	return s.size
+}
+
+// streamSource writes the content of src to w, base64-encoded. It returns an error if
+// the content is not exactly src.Size() bytes long.
+func streamSource(w io.Writer, src AttachmentSource) error {
+	r, err := src.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open attachment %s: %w", src.Filename(), err)
+	}
+	defer r.Close()
+
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	n, err := io.Copy(enc, io.LimitReader(r, src.Size()+1))
+	if err != nil {
+		return fmt.Errorf("failed to read attachment %s: %w", src.Filename(), err)
+	}
+	if n != src.Size() {
+		return fmt.Errorf("attachment %s: read %d bytes, but Size reports %d", src.Filename(), n, src.Size())
+	}
+	return enc.Close()
+}
@@ -0,0 +1,249 @@
+package sendamatic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// bytesSource is an in-memory AttachmentSource that counts how often it is opened.
+type bytesSource struct {
+	name  string
+	data  []byte
+	size  int64
+	opens atomic.Int32
+}
+
+func newBytesSource(name string, data []byte) *bytesSource {
+	return &bytesSource{name: name, data: data, size: int64(len(data))}
+}
+
+func (s *bytesSource) Open() (io.ReadCloser, error) {
+	s.opens.Add(1)
+	return io.NopCloser(bytes.NewReader(s.data)), nil
+}
+
+func (s *bytesSource) Filename() string { return s.name }
+func (s *bytesSource) MimeType() string { return "application/octet-stream" }
+func (s *bytesSource) Size() int64      { return s.size }
+
+// captureServer returns a server that records the decompressed request body and its
+// Content-Length and responds with a successful send.
+func captureServer(t *testing.T, body *[]byte, contentLength *int64) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("Failed to create gzip reader: %v", err)
+				return
+			}
+			reader = zr
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Errorf("Failed to read request body: %v", err)
+		}
+		*body = data
+		*contentLength = r.ContentLength
+		w.Write([]byte(`{"to@example.com": [200, "msg-1"]}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAttachSource(t *testing.T) {
+	content := bytes.Repeat([]byte("streamed content "), 1000)
+	src := newBytesSource("report.bin", content)
+	msg := validMessage().
+		AttachFile("inline.txt", "text/plain", []byte("inline")).
+		AttachSource(src).
+		AddTag("reports")
+
+	var body []byte
+	var contentLength int64
+	server := captureServer(t, &body, &contentLength)
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+
+	if _, err := client.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if contentLength != int64(len(body)) {
+		t.Errorf("Content-Length = %d, want %d", contentLength, len(body))
+	}
+	if got := msg.EstimatedSize(); got != int64(len(body)) {
+		t.Errorf("EstimatedSize() = %d, want %d", got, len(body))
+	}
+
+	// The payload must decode to the same message as with an in-memory attachment
+	want := validMessage().
+		AttachFile("inline.txt", "text/plain", []byte("inline")).
+		AttachFile("report.bin", "application/octet-stream", content).
+		AddTag("reports")
+	var got, expected map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("Failed to unmarshal request body: %v", err)
+	}
+	wantBody, _ := json.Marshal(want)
+	json.Unmarshal(wantBody, &expected)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("payload = %s, want %s", body, wantBody)
+	}
+}
+
+func TestAttachSource_Compressed(t *testing.T) {
+	src := newBytesSource("a.bin", []byte("data"))
+
+	var body []byte
+	var contentLength int64
+	server := captureServer(t, &body, &contentLength)
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithCompression())
+
+	if _, err := client.Send(context.Background(), validMessage().AttachSource(src)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var msg Message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("Failed to unmarshal request body: %v", err)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Data != base64.StdEncoding.EncodeToString([]byte("data")) {
+		t.Errorf("Attachments = %+v, want one with the streamed data", msg.Attachments)
+	}
+}
+
+func TestAttachSource_ReopenedOnRetry(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg Message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"to@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	src := newBytesSource("a.bin", []byte("data"))
+	client := NewClient("user", "pass", WithBaseURL(server.URL), WithRetry(1, time.Millisecond))
+	if _, err := client.Send(context.Background(), validMessage().AttachSource(src)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := src.opens.Load(); got != 2 {
+		t.Errorf("Open() called %d times, want 2", got)
+	}
+}
+
+func TestAttachSource_SizeMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"to@example.com": [200, "msg-1"]}`))
+	}))
+	defer server.Close()
+
+	src := newBytesSource("a.bin", []byte("data"))
+	src.size = 10
+	client := NewClient("user", "pass", WithBaseURL(server.URL))
+	_, err := client.Send(context.Background(), validMessage().AttachSource(src))
+	if err == nil || !strings.Contains(err.Error(), "Size reports 10") {
+		t.Errorf("Send() error = %v, want size mismatch", err)
+	}
+}
+
+func TestAttachSource_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		src  *bytesSource
+	}{
+		{"empty", newBytesSource("a.bin", nil)},
+		{"no filename", newBytesSource("", []byte("data"))},
+		{"path separator", newBytesSource("dir/a.bin", []byte("data"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := validMessage().AttachFile("a.txt", "text/plain", []byte("a")).AttachSource(tt.src)
+			var verr *ValidationError
+			if err := msg.Validate(); !errors.As(err, &verr) || verr.Field != "attachments[1]" {
+				t.Errorf("Validate() error = %v, want ValidationError for attachments[1]", err)
+			}
+		})
+	}
+
+	client := NewClient("user", "pass", WithMaxAttachmentSize(3))
+	_, err := client.Send(context.Background(), validMessage().AttachSource(newBytesSource("a.bin", []byte("data"))))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "attachments[0]" {
+		t.Errorf("Send() error = %v, want ValidationError for attachments[0]", err)
+	}
+}
+
+func TestAttachSource_Nil(t *testing.T) {
+	msg := validMessage().AttachSource(nil)
+	if len(msg.Sources()) != 0 {
+		t.Errorf("Sources() = %v, want none", msg.Sources())
+	}
+	if got, want := msg.EstimatedSize(), validMessage().EstimatedSize(); got != want {
+		t.Errorf("EstimatedSize() = %d, want %d", got, want)
+	}
+}
+
+func TestAttachSource_Clone(t *testing.T) {
+	msg := validMessage().AttachSource(newBytesSource("a.bin", []byte("a")))
+	clone := msg.Clone().AttachSource(newBytesSource("b.bin", []byte("b")))
+
+	if len(msg.Sources()) != 1 || len(clone.Sources()) != 2 {
+		t.Errorf("Sources() lengths = %d, %d, want 1, 2", len(msg.Sources()), len(clone.Sources()))
+	}
+	if msg.Reset(); len(msg.Sources()) != 0 {
+		t.Errorf("Sources() after Reset = %d, want 0", len(msg.Sources()))
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invoice.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := FileSource(path, "")
+	if err != nil {
+		t.Fatalf("FileSource() error = %v", err)
+	}
+	if src.Filename() != "invoice.pdf" || src.MimeType() != "application/octet-stream" || src.Size() != 8 {
+		t.Errorf("FileSource() = %q, %q, %d, want invoice.pdf, application/octet-stream, 8",
+			src.Filename(), src.MimeType(), src.Size())
+	}
+
+	var buf bytes.Buffer
+	if err := streamSource(&buf, src); err != nil {
+		t.Fatalf("streamSource() error = %v", err)
+	}
+	if want := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")); buf.String() != want {
+		t.Errorf("streamSource() = %q, want %q", buf.String(), want)
+	}
+
+	if _, err := FileSource(filepath.Dir(path), ""); err == nil {
+		t.Error("FileSource() error = nil, want error for a directory")
+	}
+	if _, err := FileSource(filepath.Join(filepath.Dir(path), "missing"), ""); err == nil {
+		t.Error("FileSource() error = nil, want error for a missing file")
+	}
+}
@@ -0,0 +1,133 @@
+package sendamatic
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// RFC 5321 and RFC 5322 limits enforced by ValidateStrict.
+const (
+	maxLocalPartOctets = 64
+	maxDomainOctets    = 255
+	maxAddressOctets   = 254
+)
+
+// ValidateStrict runs Validate and additionally enforces RFC length limits that
+// strict receivers apply and lenient ones ignore:
+//   - Every sender and recipient address must parse, with a local part of at most
+//     64 octets, a domain of at most 255 octets and at most 254 octets in total
+//   - The subject and every custom header must fit on a single line of at most
+//     998 octets including the header name, since the library does not fold headers
+//   - The subject and the text, HTML and AMP bodies must be valid UTF-8; otherwise
+//     JSON encoding silently replaces the invalid bytes with U+FFFD, which shows up
+//     as replacement characters in the recipient's inbox
+//
+// The returned error names the offending field and the exceeded limit. Use
+// WithStrictValidation to apply these checks to every Send. Opt-in checks enabled on
+// the message, such as RejectSenderAsRecipient, apply here as well.
+func (m *Message) ValidateStrict() error {
+	return m.validateStrict(maxRecipients)
+}
+
+// validateStrict implements ValidateStrict with the given maximum number of To recipients.
+func (m *Message) validateStrict(maxTo int) error {
+	if err := m.validate(maxTo); err != nil {
+		return err
+	}
+
+	if err := checkAddressLimits("sender", m.Sender); err != nil {
+		return err
+	}
+	for _, field := range []struct {
+		name string
+		list []string
+	}{
+		{"to", m.To},
+		{"cc", m.CC},
+		{"bcc", m.BCC},
+	} {
+		for _, email := range field.list {
+			if err := checkAddressLimits(field.name, email); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"subject", m.Subject},
+		{"text_body", m.TextBody},
+		{"html_body", m.HTMLBody},
+		{"amp_body", m.AMPBody},
+	} {
+		if err := checkUTF8(field.name, field.value); err != nil {
+			return err
+		}
+	}
+
+	if err := checkHeaderLine("subject", "Subject", m.Subject); err != nil {
+		return err
+	}
+	for i, h := range m.Headers {
+		if err := checkHeaderLine(fmt.Sprintf("headers[%d]", i), h.Header, h.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAddressLimits parses email and checks its parts against the RFC 5321 length limits.
+func checkAddressLimits(field, email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil {
+		return invalidf(field, "%s address %q is invalid: %w", field, email, err)
+	}
+
+	at := strings.LastIndex(addr.Address, "@")
+	local, domain := addr.Address[:at], addr.Address[at+1:]
+	switch {
+	case len(local) > maxLocalPartOctets:
+		return invalidf(field, "%s address %q: local part is %d octets, maximum is %d",
+			field, addr.Address, len(local), maxLocalPartOctets)
+	case len(domain) > maxDomainOctets:
+		return invalidf(field, "%s address %q: domain is %d octets, maximum is %d",
+			field, addr.Address, len(domain), maxDomainOctets)
+	case len(addr.Address) > maxAddressOctets:
+		return invalidf(field, "%s address %q: address is %d octets, maximum is %d",
+			field, addr.Address, len(addr.Address), maxAddressOctets)
+	}
+	return nil
+}
+
+// checkUTF8 returns an error naming field and the byte offset of the first invalid
+// UTF-8 sequence in s, if any.
+func checkUTF8(field, s string) error {
+	if utf8.ValidString(s) {
+		return nil
+	}
+	offset := 0
+	for offset < len(s) {
+		r, size := utf8.DecodeRuneInString(s[offset:])
+		if r == utf8.RuneError && size == 1 {
+			break
+		}
+		offset += size
+	}
+	return invalidf(field, "%s is not valid UTF-8: invalid byte 0x%02x at offset %d", field, s[offset], offset)
+}
+
+// checkHeaderLine checks that a header renders as a single line within the RFC 5322
+// limit. field names the message field holding the header in a returned error.
+func checkHeaderLine(field, name, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return invalidf(field, "header %s must not contain line breaks", name)
+	}
+	if n := len(name) + len(": ") + len(value); n > maxLineOctets {
+		return invalidf(field, "header %s line is %d octets, maximum is %d", name, n, maxLineOctets)
+	}
+	return nil
+}
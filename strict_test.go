@@ -0,0 +1,107 @@
+package sendamatic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateStrict(t *testing.T) {
+	longLocal := strings.Repeat("a", 65) + "@example.com"
+	longDomain := "user@" + strings.Repeat("d", 252) + ".com"
+	longAddress := strings.Repeat("a", 64) + "@" + strings.Repeat("d", 186) + ".com"
+
+	tests := []struct {
+		name        string
+		msg         *Message
+		wantErrText string
+	}{
+		{
+			name: "valid",
+			msg:  validMessage().AddCC("Jane Doe <jane@example.com>").AddHeader("X-Test", "value"),
+		},
+		{
+			name:        "lenient validation fails first",
+			msg:         NewMessage(),
+			wantErrText: "at least one recipient required",
+		},
+		{
+			name:        "invalid recipient",
+			msg:         validMessage().AddBCC("not-an-address"),
+			wantErrText: `bcc address "not-an-address" is invalid: mail: missing '@' or angle-addr`,
+		},
+		{
+			name:        "local part too long",
+			msg:         validMessage().AddTo(longLocal),
+			wantErrText: fmt.Sprintf("to address %q: local part is 65 octets, maximum is 64", longLocal),
+		},
+		{
+			name:        "domain too long",
+			msg:         validMessage().SetSender(longDomain),
+			wantErrText: fmt.Sprintf("sender address %q: domain is 256 octets, maximum is 255", longDomain),
+		},
+		{
+			name:        "address too long",
+			msg:         validMessage().AddCC(longAddress),
+			wantErrText: fmt.Sprintf("cc address %q: address is 255 octets, maximum is 254", longAddress),
+		},
+		{
+			name:        "subject too long",
+			msg:         validMessage().SetSubject(strings.Repeat("s", 990)),
+			wantErrText: "header Subject line is 999 octets, maximum is 998",
+		},
+		{
+			name:        "header with line break",
+			msg:         validMessage().AddHeader("X-Test", "a\r\nb"),
+			wantErrText: "header X-Test must not contain line breaks",
+		},
+		{
+			name: "valid UTF-8",
+			msg:  validMessage().SetSubject("Grüße").SetHTMLBody("<p>日本語</p>"),
+		},
+		{
+			name:        "invalid UTF-8 subject",
+			msg:         validMessage().SetSubject("Caf\xe9"),
+			wantErrText: "subject is not valid UTF-8: invalid byte 0xe9 at offset 3",
+		},
+		{
+			name:        "invalid UTF-8 text body",
+			msg:         validMessage().SetTextBody("Grüße \xff"),
+			wantErrText: "text_body is not valid UTF-8: invalid byte 0xff at offset 8",
+		},
+		{
+			name:        "invalid UTF-8 HTML body",
+			msg:         validMessage().SetHTMLBody("<p>\xc3(</p>"),
+			wantErrText: "html_body is not valid UTF-8: invalid byte 0xc3 at offset 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.ValidateStrict()
+			if tt.wantErrText == "" {
+				if err != nil {
+					t.Errorf("ValidateStrict() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErrText {
+				t.Errorf("ValidateStrict() error = %v, want %q", err, tt.wantErrText)
+			}
+		})
+	}
+}
+
+func TestWithStrictValidation(t *testing.T) {
+	msg := validMessage().AddTo(strings.Repeat("a", 65) + "@example.com")
+	if err := msg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+
+	client := NewClient("user", "pass", WithBaseURL("http://127.0.0.1:0"), WithStrictValidation())
+	_, err := client.Send(context.Background(), msg)
+	if err == nil || !strings.HasPrefix(err.Error(), "message validation failed: to address") {
+		t.Errorf("Send() error = %v, want strict validation error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package sendamatic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// webhookSignaturePrefix is the optional algorithm prefix of a webhook signature.
+const webhookSignaturePrefix = "sha256="
+
+// VerifyWebhookSignature reports whether signatureHeader is a valid signature of a
+// webhook delivery body under secret. The signature is the HMAC-SHA256 of the raw
+// request body, keyed with the webhook secret, encoded as hex (as in
+// "sha256=5d41...") or as standard base64. The "sha256=" prefix is optional.
+//
+// Sendamatic does not document whether webhook deliveries are signed, nor a header
+// name or signature algorithm for them. This function implements the common
+// HMAC-SHA256 scheme above and is only useful once the provider confirms that it
+// signs deliveries this way and in which header; until then, it cannot establish
+// that an event is genuine.
+//
+// body must be the request body exactly as received, before any JSON decoding, since
+// re-encoding changes the bytes that were signed. The comparison runs in constant
+// time.
+//
+// An error is returned if secret is empty or signatureHeader is not a well-formed
+// SHA-256 signature; a well-formed signature that does not match returns false and a
+// nil error. Reject the delivery in both cases.
+//
+// Example:
+//
+//	body, _ := io.ReadAll(r.Body)
+//	ok, err := sendamatic.VerifyWebhookSignature(secret, r.Header.Get(signatureHeader), body)
+//	if err != nil || !ok {
+//		http.Error(w, "invalid signature", http.StatusUnauthorized)
+//		return
+//	}
+func VerifyWebhookSignature(secret string, signatureHeader string, body []byte) (bool, error) {
+	if secret == "" {
+		return false, errors.New("webhook secret is empty")
+	}
+
+	sig, err := decodeWebhookSignature(signatureHeader)
+	if err != nil {
+		return false, err
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil)), nil
+}
+
+// decodeWebhookSignature decodes a hex or base64 encoded SHA-256 signature.
+func decodeWebhookSignature(header string) ([]byte, error) {
+	value := strings.TrimSpace(header)
+	if len(value) >= len(webhookSignaturePrefix) && strings.EqualFold(value[:len(webhookSignaturePrefix)], webhookSignaturePrefix) {
+		value = value[len(webhookSignaturePrefix):]
+	}
+	if value == "" {
+		return nil, errors.New("webhook signature is empty")
+	}
+
+	if sig, err := hex.DecodeString(value); err == nil && len(sig) == sha256.Size {
+		return sig, nil
+	}
+	if sig, err := base64.StdEncoding.DecodeString(value); err == nil && len(sig) == sha256.Size {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("malformed webhook signature %q: want hex or base64 encoded HMAC-SHA256", header)
+}
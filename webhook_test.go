@@ -0,0 +1,51 @@
+package sendamatic
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret := "whsec-test"
+	body := []byte(`{"event":"delivered","email":"recipient@example.com"}`)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	sum := mac.Sum(nil)
+	hexSig := hex.EncodeToString(sum)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		want    bool
+		wantErr bool
+	}{
+		{"hex", secret, hexSig, body, true, false},
+		{"prefixed hex", secret, "sha256=" + hexSig, body, true, false},
+		{"uppercase prefix", secret, "SHA256=" + hexSig, body, true, false},
+		{"base64", secret, base64.StdEncoding.EncodeToString(sum), body, true, false},
+		{"tampered body", secret, hexSig, append([]byte(" "), body...), false, false},
+		{"wrong secret", "other", hexSig, body, false, false},
+		{"empty secret", "", hexSig, body, false, true},
+		{"empty signature", secret, "", body, false, true},
+		{"truncated signature", secret, hexSig[:32], body, false, true},
+		{"garbage", secret, "sha256=not-a-signature", body, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyWebhookSignature(tt.secret, tt.header, tt.body)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyWebhookSignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyWebhookSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}